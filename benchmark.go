@@ -1,17 +1,33 @@
 // Package benchparse provides utilities for parsing benchmark results.
 // Parsed results are split by sub-benchmarks, with support for sub-benchmarks
 // with names of the form 'var_name=var_value'
+//
+// Benchmarks run with a fixed number of iterations (e.g. '-benchtime=100x')
+// produce output in the same format, so they are parsed like any other
+// benchmark. There is no way to detect from the output that the iteration
+// count was fixed, so such results can't be told apart from those of
+// benchmarks that chose their own iteration count. The reported N is still
+// the number of iterations testing.B ran, so aggregations weighted by it
+// are unaffected, but per-iteration values of very short runs may be
+// noisier than usual.
 package benchparse
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"golang.org/x/tools/benchmark/parse"
 )
@@ -20,24 +36,375 @@ import (
 type Benchmark struct {
 	Name    string
 	Results BenchResults
+
+	// Pkg is the import path of the package the benchmark was run in,
+	// which is only known when parsing output with the '-json' flag
+	// enabled.
+	Pkg string
 }
 
 // String returns the string representation of the benchmark.
 // This follows the same format as the testing.B output.
 func (b Benchmark) String() string {
-	s := make([]string, len(b.Results))
+	var s strings.Builder
+	b.WriteTo(&s) // writing to a strings.Builder never fails
+	return s.String()
+}
+
+// WriteTo writes the string representation of the benchmark to w one
+// result line at a time, implementing io.WriterTo. The number of bytes
+// written is returned along with the first error encountered, if any,
+// in which case no further lines are written.
+func (b Benchmark) WriteTo(w io.Writer) (int64, error) {
+	var total int64
 	for i, res := range b.Results {
-		s[i] = fmt.Sprintf("%s%s %s", b.Name, res.Inputs, benchOutputsString(res.Outputs))
+		sep := "\n"
+		if i == 0 {
+			sep = ""
+		}
+		n, err := fmt.Fprintf(w, "%s%s%s %s", sep, b.Name, res.Inputs, benchOutputsString(res.Outputs))
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Rename returns a copy of the benchmark with the provided name.
+// Since the full name of each result is constructed from the
+// benchmark name and the result's inputs, the results themselves
+// are unchanged.
+func (b Benchmark) Rename(newName string) Benchmark {
+	results := make(BenchResults, len(b.Results))
+	copy(results, b.Results)
+	return Benchmark{Name: newName, Results: results, Pkg: b.Pkg}
+}
+
+// Filter returns a copy of the benchmark with only the results matching
+// the provided filter expr, see BenchResults.Filter.
+func (b Benchmark) Filter(filterExpr string) (Benchmark, error) {
+	results, err := b.Results.Filter(filterExpr)
+	if err != nil {
+		return Benchmark{}, err
+	}
+	return Benchmark{Name: b.Name, Results: results, Pkg: b.Pkg}, nil
+}
+
+// Group groups the benchmark's results by a specified set of input
+// variable names, see BenchResults.Group. Each group is returned as a
+// benchmark with the same name, keyed by the group's variable values.
+func (b Benchmark) Group(groupBy []string) map[string]Benchmark {
+	grouped := b.Results.Group(groupBy)
+	benches := make(map[string]Benchmark, len(grouped))
+	for k, results := range grouped {
+		benches[k] = Benchmark{Name: b.Name, Results: results, Pkg: b.Pkg}
+	}
+	return benches
+}
+
+// SummaryLine returns a single line summarizing the benchmark's results
+// by the named metric, for example:
+//
+//	BenchmarkMath: 4 cases, ns/op 13.3–55357 (mean 18948.5)
+//
+// Results where the metric was not measured are not included in the
+// range or mean.
+func (b Benchmark) SummaryLine(metric string) string {
+	prefix := fmt.Sprintf("%s: %d cases, %s", b.Name, len(b.Results), metric)
+
+	values, err := b.Results.measuredValues(metric)
+	if err != nil {
+		return fmt.Sprintf("%s (%s)", prefix, err)
+	}
+	if len(values) == 0 {
+		return fmt.Sprintf("%s not measured", prefix)
+	}
+
+	min, max, sum := values[0], values[0], 0.0
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+		sum += v
+	}
+	return fmt.Sprintf("%s %.6g–%.6g (mean %.6g)", prefix, min, max, sum/float64(len(values)))
+}
+
+var errDuplicateVarName = errors.New("duplicate variable name")
+
+// ConformsTo checks that every result of the benchmark has exactly the
+// input variables named in schema, returning the results which do not.
+// An error is returned if the schema contains duplicate names.
+func (b Benchmark) ConformsTo(schema []string) ([]BenchRes, error) {
+	expected := make(map[string]bool, len(schema))
+	for _, name := range schema {
+		if expected[name] {
+			return nil, fmt.Errorf("%w: %s", errDuplicateVarName, name)
+		}
+		expected[name] = true
+	}
+
+	nonConforming := []BenchRes{}
+	for _, res := range b.Results {
+		var (
+			names    = res.Inputs.VarNames()
+			conforms = len(names) == len(expected)
+			seen     = make(map[string]bool, len(names))
+		)
+		for _, name := range names {
+			if !expected[name] || seen[name] {
+				conforms = false
+				break
+			}
+			seen[name] = true
+		}
+		if !conforms {
+			nonConforming = append(nonConforming, res)
+		}
+	}
+	return nonConforming, nil
+}
+
+// CaseOverlap compares the inputs of the benchmark's results with those
+// of other. Inputs present in both benchmarks are returned in common,
+// while those only present in one of the benchmarks are returned in
+// onlyLeft (b) or onlyRight (other). Inputs are matched by their string
+// representation and each distinct input is returned at most once.
+func (b Benchmark) CaseOverlap(other Benchmark) (common, onlyLeft, onlyRight []BenchInputs) {
+	var (
+		rightKeys = map[string]bool{}
+		seen      = map[string]bool{}
+	)
+	for _, res := range other.Results {
+		rightKeys[res.Inputs.key()] = true
+	}
+
+	common, onlyLeft, onlyRight = []BenchInputs{}, []BenchInputs{}, []BenchInputs{}
+	for _, res := range b.Results {
+		k := res.Inputs.key()
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		if rightKeys[k] {
+			common = append(common, res.Inputs)
+		} else {
+			onlyLeft = append(onlyLeft, res.Inputs)
+		}
+	}
+	for _, res := range other.Results {
+		k := res.Inputs.key()
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		onlyRight = append(onlyRight, res.Inputs)
+	}
+	return common, onlyLeft, onlyRight
+}
+
+// Equal reports whether the benchmark is semantically equal to other,
+// meaning they have the same name, package, and results. Results are
+// matched by their inputs and the values of their measured outputs,
+// ignoring the order of the results and the positions of the inputs
+// within the benchmark name, which makes Equal suitable for comparing
+// externally constructed benchmarks with parsed ones.
+func (b Benchmark) Equal(other Benchmark) bool {
+	if b.Name != other.Name || b.Pkg != other.Pkg || len(b.Results) != len(other.Results) {
+		return false
+	}
+
+	counts := map[string]int{}
+	for _, res := range b.Results {
+		counts[res.equalityKey()]++
+	}
+	for _, res := range other.Results {
+		k := res.equalityKey()
+		if counts[k] == 0 {
+			return false
+		}
+		counts[k]--
+	}
+	return true
+}
+
+// equalityKey returns a string identifying the result's inputs and
+// measured outputs, independent of the positions of the inputs. The
+// type of each variable's value is included, so that e.g. the int 1 and
+// the string "1" aren't treated as equal, while the value is formatted
+// independently of how it was written in the benchmark name.
+func (b BenchRes) equalityKey() string {
+	inputs := make([]string, 0, len(b.Inputs.Subs)+len(b.Inputs.VarValues))
+	for _, sub := range b.Inputs.Subs {
+		inputs = append(inputs, sub.String())
+	}
+	for _, varVal := range b.Inputs.VarValues {
+		inputs = append(inputs, varVal.Name+"="+valueKey(varVal.Value))
+	}
+	sort.Strings(inputs)
+
+	var s strings.Builder
+	s.WriteString(strings.Join(inputs, "/"))
+	fmt.Fprintf(&s, "-%d", b.Inputs.MaxProcs)
+	if b.Outputs == nil {
+		return s.String()
+	}
+	fmt.Fprintf(&s, " %d", b.Outputs.GetIterations())
+	for _, m := range measuredMetrics(b.Outputs) {
+		fmt.Fprintf(&s, " %v %s", m.value, m.metric)
+	}
+	return s.String()
+}
+
+// valueKey returns a string identifying the value of a variable for use
+// as a map key. The type is included so that e.g. the int 1 and the
+// string "1" aren't treated as equal, while unlike the value itself a
+// NaN float is equal to any other.
+func valueKey(v interface{}) string {
+	return fmt.Sprintf("%T:%v", v, v)
+}
+
+// ValueDiff describes how the values of an input variable changed
+// between two runs of a benchmark.
+type ValueDiff struct {
+	Added   []interface{} // values only present in the new run
+	Removed []interface{} // values only present in the old run
+}
+
+// VarCoverageDiff compares the input variables of two runs of a
+// benchmark, e.g. to explain why fewer cases were matched when
+// comparing them. Variables only present in new or old are returned
+// in addedVars or removedVars respectively, sorted by name. For
+// variables present in both runs, changedValues contains the values
+// which were added or removed, keyed by the variable's name. Variables
+// with the same values in both runs are not included. Values are in
+// the order they first appear.
+func VarCoverageDiff(old, new Benchmark) (addedVars, removedVars []string, changedValues map[string]ValueDiff) {
+	var (
+		oldValues, oldOrder = varValuesByName(old)
+		newValues, newOrder = varValuesByName(new)
+	)
+	addedVars, removedVars, changedValues = []string{}, []string{}, map[string]ValueDiff{}
+	for name := range newValues {
+		if _, ok := oldValues[name]; !ok {
+			addedVars = append(addedVars, name)
+		}
+	}
+	for name := range oldValues {
+		if _, ok := newValues[name]; !ok {
+			removedVars = append(removedVars, name)
+			continue
+		}
+		diff := ValueDiff{Added: []interface{}{}, Removed: []interface{}{}}
+		for _, v := range newOrder[name] {
+			if !oldValues[name][valueKey(v)] {
+				diff.Added = append(diff.Added, v)
+			}
+		}
+		for _, v := range oldOrder[name] {
+			if !newValues[name][valueKey(v)] {
+				diff.Removed = append(diff.Removed, v)
+			}
+		}
+		if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+			changedValues[name] = diff
+		}
+	}
+	sort.Strings(addedVars)
+	sort.Strings(removedVars)
+	return addedVars, removedVars, changedValues
+}
+
+// varValuesByName returns the set of values of each input variable of
+// the benchmark's results, keyed by valueKey, as well as the values in
+// the order they first appear.
+func varValuesByName(b Benchmark) (map[string]map[string]bool, map[string][]interface{}) {
+	var (
+		values = map[string]map[string]bool{}
+		order  = map[string][]interface{}{}
+	)
+	for _, res := range b.Results {
+		for _, varVal := range res.Inputs.VarValues {
+			if values[varVal.Name] == nil {
+				values[varVal.Name] = map[string]bool{}
+			}
+			if k := valueKey(varVal.Value); !values[varVal.Name][k] {
+				values[varVal.Name][k] = true
+				order[varVal.Name] = append(order[varVal.Name], varVal.Value)
+			}
+		}
+	}
+	return values, order
+}
+
+// NormalizeProcs returns a copy of the benchmarks with the MaxProcs of
+// every result set to 1, the value used for results without a GOMAXPROCS
+// suffix. This causes results which only differ by GOMAXPROCS to be
+// treated as equivalent when grouping or comparing results.
+func NormalizeProcs(benches []Benchmark) []Benchmark {
+	normalized := make([]Benchmark, len(benches))
+	for i, bench := range benches {
+		results := make(BenchResults, len(bench.Results))
+		for j, res := range bench.Results {
+			res.Inputs.MaxProcs = 1
+			results[j] = res
+		}
+		normalized[i] = Benchmark{Name: bench.Name, Results: results, Pkg: bench.Pkg}
+	}
+	return normalized
+}
+
+// ByProcs groups the benchmark's results by GOMAXPROCS, keyed by the
+// GOMAXPROCS value (e.g. "4"). This is useful for output of running
+// benchmarks with a list of values for '-cpu', where each result's
+// GOMAXPROCS is a separate dimension of the benchmark.
+func (b Benchmark) ByProcs() GroupedResults {
+	grouped := GroupedResults{}
+	for _, res := range b.Results {
+		k := strconv.Itoa(res.Inputs.MaxProcs)
+		grouped[k] = append(grouped[k], res)
+	}
+	return grouped
+}
+
+// SelectBenchmarks returns the benchmarks with a name matching pattern,
+// preserving their order. The pattern matches a name if it is either a
+// glob matching the whole name (see path.Match, e.g. 'BenchmarkParse*')
+// or a regular expression matching part of it, as with the '-bench'
+// flag (e.g. 'Parse|Filter'). A pattern which is not a valid regular
+// expression is only used as a glob.
+func SelectBenchmarks(benches []Benchmark, pattern string) []Benchmark {
+	expr, _ := regexp.Compile(pattern) // nil if invalid
+	selected := []Benchmark{}
+	for _, bench := range benches {
+		if globMatch, _ := path.Match(pattern, bench.Name); globMatch || (expr != nil && expr.MatchString(bench.Name)) {
+			selected = append(selected, bench)
+		}
 	}
-	return strings.Join(s, "\n")
+	return selected
 }
 
 // ParseBenchmarks extracts a list of Benchmarks from testing.B output.
-func ParseBenchmarks(r io.Reader) ([]Benchmark, error) {
-	return parseBenchmarks(r, func(line string) (string, error) {
-		// line already formatted in this case
-		return line, nil
-	})
+// The benchmarks are returned in the order they first appear in the
+// output, with the results of each in the order they appear.
+// Any metadata preceding the results is discarded, see ParseResultSet.
+func ParseBenchmarks(r io.Reader, opts ...ParseOption) ([]Benchmark, error) {
+	return parseBenchmarks(r, textOutput, opts...)
+}
+
+// ParseBenchmarksStrict extracts a list of Benchmarks from testing.B
+// output like ParseBenchmarks, but returns an error for lines which look
+// like results (lines starting with 'Benchmark' followed by at least one
+// other field) but can't be parsed, rather than skipping them. The error
+// includes the number and text of the offending line. Other lines, such
+// as logs and benchmark names output without results, are still ignored.
+func ParseBenchmarksStrict(r io.Reader, opts ...ParseOption) ([]Benchmark, error) {
+	opts = append([]ParseOption{func(cfg *parseConfig) { cfg.strict = true }}, opts...)
+	return parseBenchmarks(r, textOutput, opts...)
 }
 
 // benchEvent represents a single testing.B output with the '-json' flag
@@ -52,63 +419,509 @@ type benchEvent struct {
 }
 
 // ParseBenchmarksFromJSON extracts a list of benchmarks from testing.B output
-// with the '-json' flag enabled.
-func ParseBenchmarksFromJSON(r io.Reader) ([]Benchmark, error) {
-	return parseBenchmarks(r, func(line string) (string, error) {
-		var event benchEvent
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			return "", fmt.Errorf("unmarshal event: %s", err)
-		}
-		return event.Output, nil
-	})
+// with the '-json' flag enabled. Benchmarks with the same name in
+// different packages are kept separate, with the package of each set as
+// its Pkg. See ParsePackagesFromJSON to also separate the metadata of
+// each package.
+func ParseBenchmarksFromJSON(r io.Reader, opts ...ParseOption) ([]Benchmark, error) {
+	return parseBenchmarks(r, jsonEventOutput, opts...)
 }
 
-func parseBenchmarks(r io.Reader, fmtLine func(line string) (string, error)) ([]Benchmark, error) {
-	var (
-		scanner    = bufio.NewScanner(r)
-		benchmarks = map[string]Benchmark{}
-	)
-	for scanner.Scan() {
-		line, err := fmtLine(scanner.Text())
+// textOutput returns a line of plain testing.B output as the Output of an
+// event. Since most lines of real output (e.g. logs) are neither results
+// nor metadata, an empty Output is returned for lines which can't be
+// either without converting them to a string, which avoids allocating for
+// such lines.
+func textOutput(line []byte) (benchEvent, error) {
+	trimmed := bytes.TrimLeftFunc(line, unicode.IsSpace)
+	if bytes.HasPrefix(trimmed, []byte("Benchmark")) || isMetadataLine(trimmed) || isSummaryLine(trimmed) {
+		return benchEvent{Output: string(line)}, nil
+	}
+	return benchEvent{}, nil
+}
+
+// jsonEventOutput decodes a line of output with the '-json' flag enabled.
+func jsonEventOutput(line []byte) (benchEvent, error) {
+	var event benchEvent
+	if err := json.Unmarshal(line, &event); err != nil {
+		return benchEvent{}, fmt.Errorf("unmarshal event: %s", err)
+	}
+	return event, nil
+}
+
+// gzipMagic are the bytes at the start of gzip-compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ParseBenchmarksAuto extracts a list of benchmarks from testing.B output,
+// detecting whether the '-json' flag was enabled. Output which was
+// compressed with gzip (e.g. an archived 'bench.txt.gz') is detected
+// and decompressed transparently.
+func ParseBenchmarksAuto(r io.Reader, opts ...ParseOption) ([]Benchmark, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		zr, err := gzip.NewReader(br)
 		if err != nil {
 			return nil, err
 		}
-		parsed, err := parse.ParseLine(line)
+		defer zr.Close()
+		br = bufio.NewReader(zr)
+	}
+	for {
+		c, err := br.ReadByte()
 		if err != nil {
+			if err == io.EOF {
+				return []Benchmark{}, nil
+			}
+			return nil, err
+		}
+		if unicode.IsSpace(rune(c)) {
+			// leading whitespace is insignificant for either format
 			continue
 		}
+		if err := br.UnreadByte(); err != nil {
+			return nil, err
+		}
+		if c == '{' {
+			return ParseBenchmarksFromJSON(br, opts...)
+		}
+		return ParseBenchmarks(br, opts...)
+	}
+}
+
+// ParseStdin extracts a list of Benchmarks from testing.B output
+// read from os.Stdin.
+func ParseStdin(opts ...ParseOption) ([]Benchmark, error) {
+	return ParseBenchmarks(os.Stdin, opts...)
+}
+
+// ParseStdinJSON extracts a list of Benchmarks from testing.B output
+// with the '-json' flag enabled read from os.Stdin.
+func ParseStdinJSON(opts ...ParseOption) ([]Benchmark, error) {
+	return ParseBenchmarksFromJSON(os.Stdin, opts...)
+}
 
-		benchName, inputs, err := parseInfo(parsed.Name)
+// ParseStdinAuto extracts a list of Benchmarks from testing.B output
+// read from os.Stdin, detecting whether the '-json' flag was enabled.
+// This allows for usage like 'go test -bench=. | mytool' regardless
+// of whether '-json' is passed to go test.
+func ParseStdinAuto(opts ...ParseOption) ([]Benchmark, error) {
+	return ParseBenchmarksAuto(os.Stdin, opts...)
+}
+
+// ResultSet is the result of parsing testing.B output, including the
+// metadata describing the environment the benchmarks were run in which
+// is output before the results. Metadata which was not output is left
+// empty. If the output contains multiple values for the same metadata,
+// for example when benchmarking multiple packages, the first is used.
+type ResultSet struct {
+	Goos   string
+	Goarch string
+	Pkg    string
+	CPU    string // the CPU model, output since Go 1.16
+
+	// Status and Elapsed are taken from the summary lines following
+	// the results. When benchmarking multiple packages the run is
+	// only considered to have passed if every package passed, and
+	// Elapsed is the total of the elapsed time of each package.
+	Status  RunStatus
+	Elapsed time.Duration
+
+	Benchmarks []Benchmark
+}
+
+// RunStatus is the outcome of running the benchmarks, as reported by
+// the 'PASS', 'ok', and 'FAIL' lines of testing.B output. The results
+// of a failed run may be incomplete.
+type RunStatus int
+
+// The possible outcomes of running the benchmarks.
+const (
+	StatusUnknown RunStatus = iota // no summary was output, e.g. if the output was truncated
+	StatusPassed                   // a 'PASS' or 'ok' line was output
+	StatusFailed                   // a 'FAIL' or '--- FAIL:' line was output
+)
+
+// ParseResultSet extracts a ResultSet from testing.B output.
+func ParseResultSet(r io.Reader, opts ...ParseOption) (*ResultSet, error) {
+	return parseResultSet(r, textOutput, opts...)
+}
+
+// ParseResultSetFromJSON extracts a ResultSet from testing.B output with
+// the '-json' flag enabled.
+func ParseResultSetFromJSON(r io.Reader, opts ...ParseOption) (*ResultSet, error) {
+	return parseResultSet(r, jsonEventOutput, opts...)
+}
+
+// metadataKeys are the keys of the metadata lines preceding results,
+// see ResultSet.parseMetadata.
+var metadataKeys = []string{"goos", "goarch", "pkg", "cpu"}
+
+// isMetadataLine reports whether the line, with leading whitespace
+// trimmed, may be a metadata line.
+func isMetadataLine(trimmed []byte) bool {
+	for _, key := range metadataKeys {
+		if bytes.HasPrefix(trimmed, []byte(key+": ")) {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryPrefixes are the prefixes of the summary lines following
+// results, see ResultSet.parseSummary.
+var summaryPrefixes = []string{"PASS", "FAIL", "--- FAIL:", "ok ", "ok\t"}
+
+// isSummaryLine reports whether the line, with leading whitespace
+// trimmed, may be a summary line.
+func isSummaryLine(trimmed []byte) bool {
+	for _, prefix := range summaryPrefixes {
+		if bytes.HasPrefix(trimmed, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSummary records the status and elapsed time of a summary line,
+// such as 'ok  \tpkg\t1.234s' or 'FAIL', returning false if the line is
+// not a summary.
+func (rs *ResultSet) parseSummary(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+	switch {
+	case fields[0] == "PASS" && len(fields) == 1:
+		rs.setStatus(StatusPassed)
+		return true
+	case fields[0] == "---" && len(fields) > 1 && fields[1] == "FAIL:":
+		rs.setStatus(StatusFailed)
+		return true
+	case fields[0] == "ok":
+		rs.setStatus(StatusPassed)
+	case fields[0] == "FAIL":
+		rs.setStatus(StatusFailed)
+	default:
+		return false
+	}
+	// the elapsed time is omitted for e.g. '(cached)' or '[build failed]'
+	if len(fields) >= 3 {
+		if elapsed, err := time.ParseDuration(fields[2]); err == nil {
+			rs.Elapsed += elapsed
+		}
+	}
+	return true
+}
+
+// setStatus updates the status of the run, with a failure taking
+// precedence over any other status.
+func (rs *ResultSet) setStatus(status RunStatus) {
+	if rs.Status != StatusFailed {
+		rs.Status = status
+	}
+}
+
+// parseMetadata records the value of a metadata line of the form
+// 'key: value', returning false if the line is not metadata.
+func (rs *ResultSet) parseMetadata(line string) bool {
+	split := strings.SplitN(strings.TrimSpace(line), ": ", 2)
+	if len(split) != 2 {
+		return false
+	}
+	var field *string
+	switch split[0] {
+	case "goos":
+		field = &rs.Goos
+	case "goarch":
+		field = &rs.Goarch
+	case "pkg":
+		field = &rs.Pkg
+	case "cpu":
+		field = &rs.CPU
+	default:
+		return false
+	}
+	if *field == "" {
+		*field = strings.TrimSpace(split[1])
+	}
+	return true
+}
+
+// parseBenchmarks parses the benchmarks from r, using fmtLine to extract
+// the testing.B output and, if available, its timestamp and package from
+// each line.
+func parseBenchmarks(r io.Reader, fmtLine func(line []byte) (benchEvent, error), opts ...ParseOption) ([]Benchmark, error) {
+	rs, err := parseResultSet(r, fmtLine, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return rs.Benchmarks, nil
+}
+
+// parseResultSet parses the benchmarks and metadata from r, see
+// parseBenchmarks.
+func parseResultSet(r io.Reader, fmtLine func(line []byte) (benchEvent, error), opts ...ParseOption) (*ResultSet, error) {
+	var (
+		scanner = bufio.NewScanner(r)
+		builder = newResultSetBuilder(newParseConfig(opts))
+		lineNum = 0
+	)
+	for scanner.Scan() {
+		lineNum++
+		event, err := fmtLine(scanner.Bytes())
 		if err != nil {
 			return nil, err
 		}
-		bench, ok := benchmarks[benchName]
-		if !ok {
-			bench = Benchmark{Name: benchName, Results: []BenchRes{}}
+		if err := builder.add(event); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
 		}
+	}
 
-		outputs := parsedBenchOutputs{*parsed}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
 
-		bench.Results = append(bench.Results, BenchRes{
-			Inputs:  inputs,
-			Outputs: outputs,
-		})
+	return builder.build(), nil
+}
 
-		benchmarks[benchName] = bench
+// ParsePackagesFromJSON extracts a ResultSet for each package from
+// testing.B output with the '-json' flag enabled, keyed by the import
+// path of the package. Results are attributed to the package of their
+// event, so output from multiple packages is handled correctly even if
+// the events of different packages are interleaved, as happens when
+// running 'go test -json ./...' with packages benchmarked in parallel.
+//
+// Only packages which output benchmark results or metadata are included,
+// so packages which only ran tests are omitted, as are events without a
+// package.
+func ParsePackagesFromJSON(r io.Reader, opts ...ParseOption) (map[string]*ResultSet, error) {
+	var (
+		scanner  = bufio.NewScanner(r)
+		cfg      = newParseConfig(opts)
+		builders = map[string]*resultSetBuilder{}
+		lineNum  = 0
+	)
+	for scanner.Scan() {
+		lineNum++
+		event, err := jsonEventOutput(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if event.Package == "" {
+			continue
+		}
+		builder, ok := builders[event.Package]
+		if !ok {
+			if !cfg.startsResultSet(event.Output) {
+				// e.g. a package without benchmarks
+				continue
+			}
+			builder = newResultSetBuilder(cfg)
+			builders[event.Package] = builder
+		}
+		if err := builder.add(event); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
 	}
 
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
 
-	parsedBenchmarks := make([]Benchmark, len(benchmarks))
-	i := 0
-	for _, v := range benchmarks {
-		parsedBenchmarks[i] = v
-		i++
+	sets := make(map[string]*ResultSet, len(builders))
+	for pkg, builder := range builders {
+		sets[pkg] = builder.build()
+	}
+	return sets, nil
+}
+
+// startsResultSet reports whether the line of output is a benchmark
+// result or metadata, rather than e.g. the output of tests. A malformed
+// result rejected by the config is also reported, so the error is
+// returned when parsing it.
+func (cfg parseConfig) startsResultSet(line string) bool {
+	if isMetadataLine(bytes.TrimLeftFunc([]byte(line), unicode.IsSpace)) {
+		return true
+	}
+	_, _, ok, err := cfg.parseLine(line, time.Time{})
+	return ok || err != nil
+}
+
+// resultSetBuilder accumulates the results and metadata of a ResultSet
+// one line at a time.
+type resultSetBuilder struct {
+	cfg        parseConfig
+	rs         *ResultSet
+	benchmarks []Benchmark    // in the order they first appear
+	indices    map[string]int // the index of each benchmark, keyed by package and name
+	dups       duplicateChecker
+	samples    sampleCounter
+}
+
+func newResultSetBuilder(cfg parseConfig) *resultSetBuilder {
+	return &resultSetBuilder{
+		cfg:        cfg,
+		rs:         &ResultSet{},
+		benchmarks: []Benchmark{},
+		indices:    map[string]int{},
+		dups:       newDuplicateChecker(),
+		samples:    sampleCounter{},
+	}
+}
+
+// add parses the testing.B output of a single event.
+func (b *resultSetBuilder) add(event benchEvent) error {
+	line := event.Output
+	if line == "" {
+		return nil
+	}
+	if b.rs.parseMetadata(line) || b.rs.parseSummary(line) {
+		return nil
+	}
+	benchName, res, ok, err := b.cfg.parseLine(line, event.Time)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	key := benchName
+	if event.Package != "" {
+		key = event.Package + "." + benchName
+	}
+	if b.cfg.noDuplicateInputs {
+		if err := b.dups.check(key, res.Inputs); err != nil {
+			return err
+		}
 	}
+	res.sampleIndex = b.samples.next(key, res.Inputs)
+	i, ok := b.indices[key]
+	if !ok {
+		i = len(b.benchmarks)
+		b.indices[key] = i
+		b.benchmarks = append(b.benchmarks, Benchmark{Name: benchName, Results: []BenchRes{}, Pkg: event.Package})
+	}
+	b.benchmarks[i].Results = append(b.benchmarks[i].Results, res)
+	return nil
+}
+
+func (b *resultSetBuilder) build() *ResultSet {
+	b.rs.Benchmarks = b.benchmarks
+	return b.rs
+}
 
-	return parsedBenchmarks, nil
+var errDuplicateInputs = errors.New("duplicate inputs")
+
+// duplicateChecker detects results with the same inputs as a previous
+// result of the same benchmark, other than consecutive repetitions.
+type duplicateChecker struct {
+	seen map[string]map[string]bool // keyed by benchmark name then inputs
+	last map[string]string          // the last inputs of each benchmark
+}
+
+func newDuplicateChecker() duplicateChecker {
+	return duplicateChecker{seen: map[string]map[string]bool{}, last: map[string]string{}}
+}
+
+func (d duplicateChecker) check(benchName string, inputs BenchInputs) error {
+	k := inputs.key()
+	if last, ok := d.last[benchName]; ok && last == k {
+		// a repetition from running with '-count'
+		return nil
+	}
+	if d.seen[benchName][k] {
+		return fmt.Errorf("%w: %s%s", errDuplicateInputs, benchName, inputs)
+	}
+	if d.seen[benchName] == nil {
+		d.seen[benchName] = map[string]bool{}
+	}
+	d.seen[benchName][k] = true
+	d.last[benchName] = k
+	return nil
+}
+
+// sampleCounter counts the results with the same benchmark name and
+// inputs, keyed by the name followed by the inputs.
+type sampleCounter map[string]int
+
+// next returns the sample index of the next result of the benchmark
+// with the inputs, see BenchRes.SampleIndex.
+func (s sampleCounter) next(benchName string, inputs BenchInputs) int {
+	k := benchName + inputs.key()
+	i := s[k]
+	s[k]++
+	return i
+}
+
+// parseLine parses a single line of testing.B output, returning the
+// name of the benchmark and the result. False is returned if the line
+// is not a benchmark result.
+func (cfg parseConfig) parseLine(line string, timestamp time.Time) (string, BenchRes, bool, error) {
+	// wrappers like gotestsum may indent results or pad them with
+	// trailing whitespace
+	line = strings.TrimSpace(line)
+	if cfg.decimalComma {
+		line = normalizeDecimalComma(line)
+	}
+	parsed, err := parse.ParseLine(line)
+	if err != nil {
+		if cfg.strict && looksLikeResult(line) {
+			return "", BenchRes{}, false, fmt.Errorf("%w: %q: %s", errMalformedResult, line, err)
+		}
+		return "", BenchRes{}, false, nil
+	}
+
+	benchName, inputs, err := parseInfo(parsed.Name)
+	if err != nil {
+		return "", BenchRes{}, false, err
+	}
+	cfg.coerceBoolVars(inputs.VarValues)
+
+	res := BenchRes{
+		Inputs:  inputs,
+		Outputs: parsedBenchOutputs{Benchmark: *parsed, extra: parseExtraMetrics(line)},
+	}
+	if cfg.timestamps {
+		res.timestamp = timestamp
+	}
+	return benchName, res, true, nil
+}
+
+var errMalformedResult = errors.New("malformed benchmark result")
+
+// looksLikeResult reports whether the line appears to be a benchmark
+// result, i.e. a benchmark name followed by at least one other field.
+// A benchmark name on its own is output before the results of its
+// sub-benchmarks, so is not considered a result.
+func looksLikeResult(line string) bool {
+	fields := strings.Fields(line)
+	return len(fields) >= 2 && strings.HasPrefix(fields[0], "Benchmark")
+}
+
+// parseExtraMetrics extracts the custom metrics (those reported via
+// testing.B.ReportMetric) from a line of benchmark output, since these
+// are ignored by parse.ParseLine. Nil is returned if there are none.
+func parseExtraMetrics(line string) map[string]float64 {
+	var (
+		fields = strings.Fields(line)
+		extra  map[string]float64
+	)
+	// fields are the name and iterations followed by (value, unit) pairs
+	for i := 1; i < len(fields)/2; i++ {
+		quant, unit := fields[i*2], fields[i*2+1]
+		if isOutputMetric(unit) {
+			continue
+		}
+		f, err := strconv.ParseFloat(quant, 64)
+		if err != nil {
+			continue
+		}
+		if extra == nil {
+			extra = map[string]float64{}
+		}
+		extra[unit] = f
+	}
+	return extra
 }
 
 // used to trim unnecessary trailing chars from benchname
@@ -123,36 +936,44 @@ func parseInfo(s string) (string, BenchInputs, error) {
 	info := submatches[1]
 	// number at the end of benchmark name represents GOMAXPROCS: https://golang.org/src/testing/benchmark.go#L548
 	if len(submatches) == 3 && submatches[2] != "" {
-		var err error
-		maxProcs, err = strconv.Atoi(submatches[2])
-		if err != nil {
-			return "", BenchInputs{}, fmt.Errorf("error parsing maxprocs: %w", err)
+		procs, err := strconv.Atoi(submatches[2])
+		if err == nil && procs > 0 {
+			maxProcs = procs
+		} else {
+			// not a valid GOMAXPROCS (e.g. from a corrupted log), so
+			// treat the suffix as part of the name rather than failing
+			info = s
 		}
 	}
+	// count the variables and subs up front so that each slice is only
+	// allocated once, since this is called for every result
+	numSubs, numVars := 0, 0
+	for rest, ok := nextSub(info); ok; rest, ok = nextSub(rest) {
+		if strings.IndexByte(subName(rest), '=') >= 0 {
+			numVars++
+		} else {
+			numSubs++
+		}
+	}
+
 	var (
-		name      string
-		varValues = []BenchVarValue{}
-		subs      = []BenchSub{}
-		bySub     = strings.Split(info, "/")
+		name      = subName(info)
+		varValues = make([]BenchVarValue, 0, numVars)
+		subs      = make([]BenchSub, 0, numSubs)
+		position  = 0
 	)
+	for rest, ok := nextSub(info); ok; rest, ok = nextSub(rest) {
+		position++
+		sub := subName(rest)
 
-	for i, sub := range bySub {
-		if i == 0 {
-			name = sub
-			continue
-		}
-
-		split := strings.Split(sub, "=")
-		if len(split) == 2 {
-			varValues = append(varValues, BenchVarValue{
-				Name:     split[0],
-				Value:    value(split[1]),
-				position: i,
-			})
+		// only the first '=' separates the name from the value, so
+		// e.g. 'query=a=b' is the variable query with the value 'a=b'
+		if i := strings.IndexByte(sub, '='); i >= 0 {
+			varValues = append(varValues, newVarValue(sub[:i], sub[i+1:], position))
 		} else {
 			subs = append(subs, BenchSub{
 				Name:     sub,
-				position: i,
+				position: position,
 			})
 		}
 	}
@@ -160,17 +981,79 @@ func parseInfo(s string) (string, BenchInputs, error) {
 	return name, BenchInputs{VarValues: varValues, Subs: subs, MaxProcs: maxProcs}, nil
 }
 
+// subName returns the part of the benchmark name s up to the next '/'.
+func subName(s string) string {
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// nextSub returns the part of the benchmark name s following the next
+// '/', or false if there is none.
+func nextSub(s string) (string, bool) {
+	i := strings.IndexByte(s, '/')
+	if i < 0 {
+		return "", false
+	}
+	return s[i+1:], true
+}
+
+// newVarValue returns the named variable with the value parsed from s,
+// see value. If the value would be rendered differently than s, s is
+// kept for rendering so that the benchmark name can be reproduced.
+func newVarValue(name, s string, position int) BenchVarValue {
+	v := BenchVarValue{Name: name, Value: value(s), position: position}
+	if keepsText(v.Value) && v.formatValue() != s {
+		v.text = s
+	}
+	return v
+}
+
+// keepsText reports whether the original text of the parsed value
+// should be kept for rendering.
+func keepsText(v interface{}) bool {
+	switch v.(type) {
+	case ByteSize, time.Duration, int:
+		return true
+	default:
+		return false
+	}
+}
+
+// value parses the value of an input variable as an int (or a uint64
+// if too large for an int), float64, bool, time.Duration (e.g.
+// 'timeout=500ms'), or ByteSize (e.g. 'size=4KB'), in that order,
+// falling back to the string itself.
+//
+// Integers with a base prefix (e.g. 'mask=0xff') are parsed in that
+// base, while those without one are always parsed as decimal so that
+// zero padded values like 'id=010' keep their meaning. Scientific
+// notation (e.g. 'n=1e6') is parsed as a float64.
 func value(s string) interface{} {
 	convs := []func(str string) (interface{}, error){
 		func(str string) (interface{}, error) {
 			return strconv.Atoi(str)
 		},
+		func(str string) (interface{}, error) {
+			// too large for an int, but can be compared exactly
+			return strconv.ParseUint(str, 10, 64)
+		},
+		func(str string) (interface{}, error) {
+			return parsePrefixedInt(str)
+		},
 		func(str string) (interface{}, error) {
 			return strconv.ParseFloat(str, 64)
 		},
 		func(str string) (interface{}, error) {
 			return strconv.ParseBool(str)
 		},
+		func(str string) (interface{}, error) {
+			return time.ParseDuration(str)
+		},
+		func(str string) (interface{}, error) {
+			return parseByteSize(str)
+		},
 	}
 
 	for _, conv := range convs {
@@ -181,3 +1064,16 @@ func value(s string) interface{} {
 
 	return s
 }
+
+var errNoBasePrefix = errors.New("no base prefix")
+
+// parsePrefixedInt parses an integer with a '0x', '0o', or '0b' base
+// prefix, optionally preceded by a sign.
+func parsePrefixedInt(s string) (int, error) {
+	unsigned := strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	if len(unsigned) < 2 || unsigned[0] != '0' || !strings.ContainsRune("xXoObB", rune(unsigned[1])) {
+		return 0, errNoBasePrefix
+	}
+	i, err := strconv.ParseInt(s, 0, strconv.IntSize)
+	return int(i), err
+}
@@ -2,14 +2,17 @@ package benchparse
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"reflect"
-	"sort"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/tools/benchmark/parse"
 )
@@ -44,7 +47,7 @@ var sampleBench = Benchmark{
 				},
 				MaxProcs: 4,
 			},
-			Outputs: parsedBenchOutputs{parse.Benchmark{Name: "BenchmarkMath/areaUnder/y=sin(x)/delta=0.001000/start_x=-2/end_x=1/abs_val=true-4", N: 21801, NsPerOp: 55357, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}},
+			Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkMath/areaUnder/y=sin(x)/delta=0.001000/start_x=-2/end_x=1/abs_val=true-4", N: 21801, NsPerOp: 55357, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}},
 		},
 		{
 			Inputs: BenchInputs{
@@ -58,7 +61,7 @@ var sampleBench = Benchmark{
 				},
 				MaxProcs: 4,
 			},
-			Outputs: parsedBenchOutputs{parse.Benchmark{Name: "BenchmarkMath/areaUnder/y=2x+3/delta=1.000000/start_x=-1/end_x=2/abs_val=false-4", N: 88335925, NsPerOp: 13.3, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}},
+			Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkMath/areaUnder/y=2x+3/delta=1.000000/start_x=-1/end_x=2/abs_val=false-4", N: 88335925, NsPerOp: 13.3, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}},
 		},
 		{
 			Inputs: BenchInputs{
@@ -71,7 +74,7 @@ var sampleBench = Benchmark{
 				},
 				MaxProcs: 4,
 			},
-			Outputs: parsedBenchOutputs{parse.Benchmark{Name: "BenchmarkMath/max/y=2x+3/delta=0.001000/start_x=-2/end_x=1-4", N: 56282, NsPerOp: 20361, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}},
+			Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkMath/max/y=2x+3/delta=0.001000/start_x=-2/end_x=1-4", N: 56282, NsPerOp: 20361, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}},
 		},
 		{
 			Inputs: BenchInputs{
@@ -84,7 +87,7 @@ var sampleBench = Benchmark{
 				},
 				MaxProcs: 4,
 			},
-			Outputs: parsedBenchOutputs{parse.Benchmark{Name: "BenchmarkMath/max/y=sin(x)/delta=1.000000/start_x=-1/end_x=2-4", N: 16381138, NsPerOp: 62.7, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}},
+			Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkMath/max/y=sin(x)/delta=1.000000/start_x=-1/end_x=2-4", N: 16381138, NsPerOp: 62.7, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}},
 		},
 	},
 }
@@ -123,7 +126,7 @@ var parseBenchmarksTests = map[string]struct {
 						Subs:     []BenchSub{},
 						MaxProcs: 4,
 					},
-					Outputs: parsedBenchOutputs{parse.Benchmark{Name: "BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=5-4", N: 37098, NsPerOp: 31052, MBPerS: 5.31, Measured: parse.NsPerOp | parse.MBPerS}},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=5-4", N: 37098, NsPerOp: 31052, MBPerS: 5.31, Measured: parse.NsPerOp | parse.MBPerS}},
 				},
 				{
 					Inputs: BenchInputs{
@@ -134,7 +137,7 @@ var parseBenchmarksTests = map[string]struct {
 						Subs:     []BenchSub{},
 						MaxProcs: 4,
 					},
-					Outputs: parsedBenchOutputs{parse.Benchmark{Name: "BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=10-4", N: 23004, NsPerOp: 52099, MBPerS: 6.33, Measured: parse.NsPerOp | parse.MBPerS}},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=10-4", N: 23004, NsPerOp: 52099, MBPerS: 6.33, Measured: parse.NsPerOp | parse.MBPerS}},
 				},
 			},
 		}},
@@ -159,7 +162,7 @@ var parseBenchmarksTests = map[string]struct {
 							Subs:     []BenchSub{},
 							MaxProcs: 1,
 						},
-						Outputs: parsedBenchOutputs{parse.Benchmark{Name: "BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=5", N: 37098, NsPerOp: 31052, Measured: parse.NsPerOp}},
+						Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=5", N: 37098, NsPerOp: 31052, Measured: parse.NsPerOp}},
 					},
 					{
 						Inputs: BenchInputs{
@@ -170,7 +173,7 @@ var parseBenchmarksTests = map[string]struct {
 							Subs:     []BenchSub{},
 							MaxProcs: 1,
 						},
-						Outputs: parsedBenchOutputs{parse.Benchmark{Name: "BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=10", N: 23004, NsPerOp: 52099, Measured: parse.NsPerOp}},
+						Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=10", N: 23004, NsPerOp: 52099, Measured: parse.NsPerOp}},
 					},
 				},
 			},
@@ -186,7 +189,7 @@ var parseBenchmarksTests = map[string]struct {
 							Subs:     []BenchSub{},
 							MaxProcs: 1,
 						},
-						Outputs: parsedBenchOutputs{parse.Benchmark{Name: "BenchmarkParseInfo/num_values=1/dtype=int", N: 624967, NsPerOp: 1721, Measured: parse.NsPerOp}},
+						Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkParseInfo/num_values=1/dtype=int", N: 624967, NsPerOp: 1721, Measured: parse.NsPerOp}},
 					},
 					{
 						Inputs: BenchInputs{
@@ -197,12 +200,118 @@ var parseBenchmarksTests = map[string]struct {
 							Subs:     []BenchSub{},
 							MaxProcs: 1,
 						},
-						Outputs: parsedBenchOutputs{parse.Benchmark{Name: "BenchmarkParseInfo/num_values=1/dtype=float64", N: 509164, NsPerOp: 2239, Measured: parse.NsPerOp}},
+						Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkParseInfo/num_values=1/dtype=float64", N: 509164, NsPerOp: 2239, Measured: parse.NsPerOp}},
 					},
 				},
 			},
 		},
 	},
+	"gotestsum_standard_verbose": {
+		resultSet: "goos: darwin\n" +
+			"goarch: amd64\n" +
+			"pkg: github.com/ShawnROGrady/mathtest\n" +
+			"=== RUN   BenchmarkFoo\n" +
+			"BenchmarkFoo\n" +
+			"BenchmarkFoo/size=1\n" +
+			"    BenchmarkFoo/size=1-4 37098 31052 ns/op\n" +
+			"BenchmarkFoo/size=2\n" +
+			" \t  BenchmarkFoo/size=2-4\t \t23004   52099 ns/op  \n" +
+			"PASS\n" +
+			"ok  \tgithub.com/ShawnROGrady/mathtest\t3.712s\n",
+		expectedBenchmarks: []Benchmark{{
+			Name: "BenchmarkFoo",
+			Results: []BenchRes{
+				{
+					Inputs: BenchInputs{
+						VarValues: []BenchVarValue{{Name: "size", Value: 1, position: 1}},
+						Subs:      []BenchSub{},
+						MaxProcs:  4,
+					},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkFoo/size=1-4", N: 37098, NsPerOp: 31052, Measured: parse.NsPerOp}},
+				},
+				{
+					Inputs: BenchInputs{
+						VarValues: []BenchVarValue{{Name: "size", Value: 2, position: 1}},
+						Subs:      []BenchSub{},
+						MaxProcs:  4,
+					},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkFoo/size=2-4", N: 23004, NsPerOp: 52099, Measured: parse.NsPerOp}},
+				},
+			},
+		}},
+	},
+	"fixed_iterations": {
+		resultSet: `
+			BenchmarkFoo/size=1-4             100             31052 ns/op
+			BenchmarkFoo/size=2-4             100             52099 ns/op
+			`,
+		expectedBenchmarks: []Benchmark{{
+			Name: "BenchmarkFoo",
+			Results: []BenchRes{
+				{
+					Inputs: BenchInputs{
+						VarValues: []BenchVarValue{{Name: "size", Value: 1, position: 1}},
+						Subs:      []BenchSub{},
+						MaxProcs:  4,
+					},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkFoo/size=1-4", N: 100, NsPerOp: 31052, Measured: parse.NsPerOp}},
+				},
+				{
+					Inputs: BenchInputs{
+						VarValues: []BenchVarValue{{Name: "size", Value: 2, position: 1}},
+						Subs:      []BenchSub{},
+						MaxProcs:  4,
+					},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkFoo/size=2-4", N: 100, NsPerOp: 52099, Measured: parse.NsPerOp}},
+				},
+			},
+		}},
+	},
+	"empty_sub_name": {
+		resultSet: `
+			BenchmarkFoo//bar/baz=1-4             37098             31052 ns/op
+			`,
+		expectedBenchmarks: []Benchmark{{
+			Name: "BenchmarkFoo",
+			Results: []BenchRes{
+				{
+					Inputs: BenchInputs{
+						VarValues: []BenchVarValue{
+							{Name: "baz", Value: 1, position: 3},
+						},
+						Subs: []BenchSub{
+							{Name: "", position: 1},
+							{Name: "bar", position: 2},
+						},
+						MaxProcs: 4,
+					},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkFoo//bar/baz=1-4", N: 37098, NsPerOp: 31052, Measured: parse.NsPerOp}},
+				},
+			},
+		}},
+	},
+	"equals_in_value": {
+		resultSet: `
+			BenchmarkQuery/query=a=b/expr=x==1/empty=-4             37098             31052 ns/op
+			`,
+		expectedBenchmarks: []Benchmark{{
+			Name: "BenchmarkQuery",
+			Results: []BenchRes{
+				{
+					Inputs: BenchInputs{
+						VarValues: []BenchVarValue{
+							{Name: "query", Value: "a=b", position: 1},
+							{Name: "expr", Value: "x==1", position: 2},
+							{Name: "empty", Value: "", position: 3},
+						},
+						Subs:     []BenchSub{},
+						MaxProcs: 4,
+					},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkQuery/query=a=b/expr=x==1/empty=-4", N: 37098, NsPerOp: 31052, Measured: parse.NsPerOp}},
+				},
+			},
+		}},
+	},
 }
 
 func TestParseBencharks(t *testing.T) {
@@ -221,11 +330,6 @@ func TestParseBencharks(t *testing.T) {
 				t.Fatalf("unexpectedly no error")
 			}
 
-			// sort the benchmarks by name for consistent results
-			sort.Slice(benchmarks, func(i, j int) bool {
-				return benchmarks[i].Name < benchmarks[j].Name
-			})
-
 			if !reflect.DeepEqual(benchmarks, testCase.expectedBenchmarks) {
 				t.Errorf("unexpected parsed benchmarks\nexpected:\n%v\nactual:\n%v", testCase.expectedBenchmarks, benchmarks)
 			}
@@ -233,6 +337,14 @@ func TestParseBencharks(t *testing.T) {
 	}
 }
 
+// sampleJSONBench is sampleBench as parsed from output with the '-json'
+// flag enabled, which includes the package.
+var sampleJSONBench = Benchmark{
+	Name:    sampleBench.Name,
+	Results: sampleBench.Results,
+	Pkg:     "github.com/ShawnROGrady/mathtest",
+}
+
 var parseBenchmarksFromJSONTests = map[string]struct {
 	resultSet          string
 	expectedBenchmarks []Benchmark
@@ -262,7 +374,39 @@ var parseBenchmarksFromJSONTests = map[string]struct {
 {"Time":"2020-05-13T22:57:01.997351-05:00","Action":"output","Package":"github.com/ShawnROGrady/mathtest","Output":"PASS\n"}
 {"Time":"2020-05-13T22:57:01.9975-05:00","Action":"output","Package":"github.com/ShawnROGrady/mathtest","Output":"ok  \tgithub.com/ShawnROGrady/mathtest\t374.272s\n"}
 {"Time":"2020-05-13T22:57:01.998418-05:00","Action":"pass","Package":"github.com/ShawnROGrady/mathtest","Elapsed":374.273}`,
-		expectedBenchmarks: []Benchmark{sampleBench},
+		expectedBenchmarks: []Benchmark{sampleJSONBench},
+	},
+	"same_name_different_packages": {
+		resultSet: `{"Action":"output","Package":"example.com/foo","Output":"BenchmarkParse-4   \t     100\t        10 ns/op\n"}
+{"Action":"output","Package":"example.com/bar","Output":"BenchmarkParse-4   \t     100\t        20 ns/op\n"}
+{"Action":"output","Package":"example.com/foo","Output":"BenchmarkParse-4   \t     100\t        30 ns/op\n"}`,
+		expectedBenchmarks: []Benchmark{
+			{
+				Name: "BenchmarkParse",
+				Results: []BenchRes{
+					{
+						Inputs:  BenchInputs{VarValues: []BenchVarValue{}, Subs: []BenchSub{}, MaxProcs: 4},
+						Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkParse-4", N: 100, NsPerOp: 10, Measured: parse.NsPerOp}},
+					},
+					{
+						Inputs:      BenchInputs{VarValues: []BenchVarValue{}, Subs: []BenchSub{}, MaxProcs: 4},
+						Outputs:     parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkParse-4", N: 100, NsPerOp: 30, Measured: parse.NsPerOp}},
+						sampleIndex: 1,
+					},
+				},
+				Pkg: "example.com/foo",
+			},
+			{
+				Name: "BenchmarkParse",
+				Results: []BenchRes{
+					{
+						Inputs:  BenchInputs{VarValues: []BenchVarValue{}, Subs: []BenchSub{}, MaxProcs: 4},
+						Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkParse-4", N: 100, NsPerOp: 20, Measured: parse.NsPerOp}},
+					},
+				},
+				Pkg: "example.com/bar",
+			},
+		},
 	},
 	"non_json": {
 		resultSet: `
@@ -274,6 +418,71 @@ var parseBenchmarksFromJSONTests = map[string]struct {
 	},
 }
 
+func TestBenchmarkNameRoundTrip(t *testing.T) {
+	names := []string{
+		"BenchmarkFoo/size=1024KB/n=1000B-4",
+		"BenchmarkFoo/size=4KiB/buf=1.5KB-4",
+		"BenchmarkFoo/timeout=60s/delay=1000ms-4",
+		"BenchmarkFoo/mask=0xff/flags=-0b101/id=010-4",
+	}
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			benches, err := ParseBenchmarks(strings.NewReader(name + " 100 10 ns/op"))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if s := strings.Fields(benches[0].String())[0]; s != name {
+				t.Errorf("unexpected name (expected=%s, actual=%s)", name, s)
+			}
+
+			data, err := json.Marshal(benches[0])
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var decoded Benchmark
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if s := strings.Fields(decoded.String())[0]; s != name {
+				t.Errorf("unexpected name after JSON round trip (expected=%s, actual=%s)", name, s)
+			}
+		})
+	}
+}
+
+func TestParseBenchmarksOrder(t *testing.T) {
+	input := `
+BenchmarkZeta/size=1-4 100 10 ns/op
+BenchmarkAlpha/size=1-4 100 20 ns/op
+BenchmarkZeta/size=2-4 100 30 ns/op
+BenchmarkMid-4 100 40 ns/op
+BenchmarkAlpha/size=2-4 100 50 ns/op
+`
+	for i := 0; i < 10; i++ {
+		benches, err := ParseBenchmarks(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		names := make([]string, len(benches))
+		for j, bench := range benches {
+			names[j] = bench.Name
+		}
+		expectedNames := []string{"BenchmarkZeta", "BenchmarkAlpha", "BenchmarkMid"}
+		if !reflect.DeepEqual(names, expectedNames) {
+			t.Fatalf("unexpected benchmark order\nexpected:\n%v\nactual:\n%v", expectedNames, names)
+		}
+
+		nsPerOp, err := benches[0].Results.measuredValues("ns/op")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if expected := []float64{10, 30}; !reflect.DeepEqual(nsPerOp, expected) {
+			t.Fatalf("unexpected results of %s\nexpected:\n%v\nactual:\n%v", names[0], expected, nsPerOp)
+		}
+	}
+}
+
 func TestParseBencharksFromJSON(t *testing.T) {
 	for testName, testCase := range parseBenchmarksFromJSONTests {
 		t.Run(testName, func(t *testing.T) {
@@ -290,11 +499,6 @@ func TestParseBencharksFromJSON(t *testing.T) {
 				t.Fatalf("unexpectedly no error")
 			}
 
-			// sort the benchmarks by name for consistent results
-			sort.Slice(benchmarks, func(i, j int) bool {
-				return benchmarks[i].Name < benchmarks[j].Name
-			})
-
 			if !reflect.DeepEqual(benchmarks, testCase.expectedBenchmarks) {
 				t.Errorf("unexpected parsed benchmarks\nexpected:\n%v\nactual:\n%v", testCase.expectedBenchmarks, benchmarks)
 			}
@@ -302,6 +506,56 @@ func TestParseBencharksFromJSON(t *testing.T) {
 	}
 }
 
+func TestParseBenchmarksAuto(t *testing.T) {
+	inputs := map[string]struct {
+		input    string
+		expected Benchmark
+	}{
+		"text": {parseBenchmarksTests["1_bench_4_cases_benchmem_set"].resultSet, sampleBench},
+		"json": {parseBenchmarksFromJSONTests["1_bench_4_cases_benchmem_set"].resultSet, sampleJSONBench},
+	}
+	for testName, testCase := range inputs {
+		input, expected := testCase.input, []Benchmark{testCase.expected}
+		t.Run(testName, func(t *testing.T) {
+			benchmarks, err := ParseBenchmarksAuto(strings.NewReader("\n  " + input))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(benchmarks, expected) {
+				t.Errorf("unexpected parsed benchmarks\nexpected:\n%v\nactual:\n%v", expected, benchmarks)
+			}
+		})
+		t.Run(testName+"_gzip", func(t *testing.T) {
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			if _, err := zw.Write([]byte(input)); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if err := zw.Close(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			benchmarks, err := ParseBenchmarksAuto(&buf)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(benchmarks, expected) {
+				t.Errorf("unexpected parsed benchmarks\nexpected:\n%v\nactual:\n%v", expected, benchmarks)
+			}
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		benchmarks, err := ParseBenchmarksAuto(strings.NewReader(" \n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(benchmarks) != 0 {
+			t.Errorf("unexpected benchmarks: %v", benchmarks)
+		}
+	})
+}
+
 type badReader struct{}
 
 func (b badReader) Read([]byte) (int, error) { return 0, errors.New("test error") }
@@ -321,10 +575,10 @@ var benchmarkStringTests = map[string]struct {
 	"benchmem_enabled": {
 		bench: sampleBench,
 		// slightly different float precision than input
-		expectedString: `BenchmarkMath/areaUnder/y=sin(x)/delta=0.001000/start_x=-2/end_x=1/abs_val=true-4 21801 55357.00 ns/op 0 B/op 0 allocs/op
-BenchmarkMath/areaUnder/y=2x+3/delta=1.000000/start_x=-1/end_x=2/abs_val=false-4 88335925 13.30 ns/op 0 B/op 0 allocs/op
-BenchmarkMath/max/y=2x+3/delta=0.001000/start_x=-2/end_x=1-4 56282 20361.00 ns/op 0 B/op 0 allocs/op
-BenchmarkMath/max/y=sin(x)/delta=1.000000/start_x=-1/end_x=2-4 16381138 62.70 ns/op 0 B/op 0 allocs/op`,
+		expectedString: `BenchmarkMath/areaUnder/y=sin(x)/delta=0.001/start_x=-2/end_x=1/abs_val=true-4 21801 55357.00 ns/op 0 B/op 0 allocs/op
+BenchmarkMath/areaUnder/y=2x+3/delta=1.0/start_x=-1/end_x=2/abs_val=false-4 88335925 13.30 ns/op 0 B/op 0 allocs/op
+BenchmarkMath/max/y=2x+3/delta=0.001/start_x=-2/end_x=1-4 56282 20361.00 ns/op 0 B/op 0 allocs/op
+BenchmarkMath/max/y=sin(x)/delta=1.0/start_x=-1/end_x=2-4 16381138 62.70 ns/op 0 B/op 0 allocs/op`,
 	},
 	"bytes_set": {
 		bench: Benchmark{
@@ -339,7 +593,7 @@ BenchmarkMath/max/y=sin(x)/delta=1.000000/start_x=-1/end_x=2-4 16381138 62.70 ns
 						Subs:     []BenchSub{},
 						MaxProcs: 4,
 					},
-					Outputs: parsedBenchOutputs{parse.Benchmark{N: 37098, NsPerOp: 31052, MBPerS: 5.31, Measured: parse.NsPerOp | parse.MBPerS}},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 37098, NsPerOp: 31052, MBPerS: 5.31, Measured: parse.NsPerOp | parse.MBPerS}},
 				},
 				{
 					Inputs: BenchInputs{
@@ -350,7 +604,7 @@ BenchmarkMath/max/y=sin(x)/delta=1.000000/start_x=-1/end_x=2-4 16381138 62.70 ns
 						Subs:     []BenchSub{},
 						MaxProcs: 4,
 					},
-					Outputs: parsedBenchOutputs{parse.Benchmark{N: 23004, NsPerOp: 52099, MBPerS: 6.33, Measured: parse.NsPerOp | parse.MBPerS}},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 23004, NsPerOp: 52099, MBPerS: 6.33, Measured: parse.NsPerOp | parse.MBPerS}},
 				},
 			},
 		},
@@ -370,7 +624,7 @@ BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=10-4 23004 52099.00 ns
 						Subs:     []BenchSub{},
 						MaxProcs: 1,
 					},
-					Outputs: parsedBenchOutputs{parse.Benchmark{N: 37098, NsPerOp: 31052, Measured: parse.NsPerOp}},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 37098, NsPerOp: 31052, Measured: parse.NsPerOp}},
 				},
 				{
 					Inputs: BenchInputs{
@@ -381,13 +635,51 @@ BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=10-4 23004 52099.00 ns
 						Subs:     []BenchSub{},
 						MaxProcs: 1,
 					},
-					Outputs: parsedBenchOutputs{parse.Benchmark{N: 23004, NsPerOp: 52099, Measured: parse.NsPerOp}},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 23004, NsPerOp: 52099, Measured: parse.NsPerOp}},
 				},
 			},
 		},
 		expectedString: `BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=5 37098 31052.00 ns/op
 BenchmarkParseBenchmarks/num_benchmarks=1/cases_per_bench=10 23004 52099.00 ns/op`,
 	},
+	"allocs_without_ns_per_op": {
+		bench: Benchmark{
+			Name: "BenchmarkFoo",
+			Results: []BenchRes{
+				{
+					Inputs: BenchInputs{
+						VarValues: []BenchVarValue{},
+						Subs:      []BenchSub{},
+						MaxProcs:  4,
+					},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 37098, AllocedBytesPerOp: 4321, AllocsPerOp: 21, Measured: parse.AllocedBytesPerOp | parse.AllocsPerOp}},
+				},
+			},
+		},
+		expectedString: `BenchmarkFoo-4 37098 4321 B/op 21 allocs/op`,
+	},
+	"empty_sub_name": {
+		bench: Benchmark{
+			Name: "BenchmarkFoo",
+			Results: []BenchRes{
+				{
+					Inputs: BenchInputs{
+						VarValues: []BenchVarValue{
+							{Name: "baz", Value: 1, position: 4},
+						},
+						Subs: []BenchSub{
+							{Name: "", position: 1},
+							{Name: "bar", position: 2},
+							{Name: "", position: 3},
+						},
+						MaxProcs: 1,
+					},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 37098, NsPerOp: 31052, Measured: parse.NsPerOp}},
+				},
+			},
+		},
+		expectedString: `BenchmarkFoo//bar//baz=1 37098 31052.00 ns/op`,
+	},
 }
 
 func TestBenchmarkString(t *testing.T) {
@@ -408,6 +700,376 @@ func TestBenchmarkString(t *testing.T) {
 	}
 }
 
+// limitedWriter writes at most n bytes before returning an error.
+type limitedWriter struct {
+	buf bytes.Buffer
+	n   int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.n-l.buf.Len() {
+		written, _ := l.buf.Write(p[:l.n-l.buf.Len()])
+		return written, errors.New("write limit reached")
+	}
+	return l.buf.Write(p)
+}
+
+func TestBenchmarkWriteTo(t *testing.T) {
+	expected := sampleBench.String()
+
+	var buf bytes.Buffer
+	n, err := sampleBench.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("unexpected output\nexpected:\n%s\nactual:\n%s", expected, buf.String())
+	}
+	if n != int64(len(expected)) {
+		t.Errorf("unexpected byte count (expected=%d, actual=%d)", len(expected), n)
+	}
+
+	firstLine := strings.Index(expected, "\n")
+	w := &limitedWriter{n: firstLine + 10}
+	n, err = sampleBench.WriteTo(w)
+	if err == nil {
+		t.Fatalf("unexpectedly no error")
+	}
+	if n != int64(w.n) {
+		t.Errorf("unexpected byte count after error (expected=%d, actual=%d)", w.n, n)
+	}
+	if w.buf.String() != expected[:w.n] {
+		t.Errorf("unexpected output after error\nexpected:\n%s\nactual:\n%s", expected[:w.n], w.buf.String())
+	}
+}
+
+func TestCustomMetricsRoundTrip(t *testing.T) {
+	var (
+		input          = "BenchmarkFoo/bar=1-8 \t100\t12.3 ns/op\t4.5 items/op\t0.9 hits/op"
+		expectedString = "BenchmarkFoo/bar=1-8 100 12.30 ns/op 0.9 hits/op 4.5 items/op"
+	)
+
+	benches, err := ParseBenchmarks(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(benches) != 1 {
+		t.Fatalf("unexpected number of benchmarks (expected=1, actual=%d)", len(benches))
+	}
+	hits, err := benches[0].Results[0].Outputs.(CustomOutputs).GetCustom("hits/op")
+	if err != nil {
+		t.Fatalf("unexpected error getting custom metric: %s", err)
+	}
+	if hits != 0.9 {
+		t.Errorf("unexpected hits/op (expected=0.9, actual=%v)", hits)
+	}
+
+	s := benches[0].String()
+	if s != expectedString {
+		t.Errorf("unexpected string\nexpected:\n%s\nactual:\n%s", expectedString, s)
+	}
+
+	reparsed, err := ParseBenchmarks(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("unexpected error re-parsing: %s", err)
+	}
+	if reparsed[0].String() != s {
+		t.Errorf("unexpected string after round trip\nexpected:\n%s\nactual:\n%s", s, reparsed[0].String())
+	}
+}
+
+func TestBenchmarkRename(t *testing.T) {
+	renamed := sampleBench.Rename("BenchmarkCalculus")
+	if renamed.Name != "BenchmarkCalculus" {
+		t.Errorf("unexpected name (expected=BenchmarkCalculus, actual=%s)", renamed.Name)
+	}
+	if sampleBench.Name != "BenchmarkMath" {
+		t.Errorf("original benchmark unexpectedly renamed to %s", sampleBench.Name)
+	}
+	if !reflect.DeepEqual(renamed.Results, sampleBench.Results) {
+		t.Errorf("unexpected results\nexpected:\n%v\nactual:\n%v", sampleBench.Results, renamed.Results)
+	}
+
+	expectedString := strings.Replace(sampleBench.String(), "BenchmarkMath", "BenchmarkCalculus", -1)
+	if renamed.String() != expectedString {
+		t.Errorf("unexpected string\nexpected:\n%s\nactual:\n%s", expectedString, renamed.String())
+	}
+}
+
+func TestBenchmarkFilter(t *testing.T) {
+	filtered, err := sampleBench.Filter("y==sin(x)")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := Benchmark{Name: sampleBench.Name, Results: BenchResults{sampleBench.Results[0], sampleBench.Results[3]}}
+	if !reflect.DeepEqual(filtered, expected) {
+		t.Errorf("unexpected filtered benchmark\nexpected:\n%v\nactual:\n%v", expected, filtered)
+	}
+
+	if _, err := sampleBench.Filter("y,2"); !errors.Is(err, errMalformedFilter) {
+		t.Errorf("unexpected error\nexpected=%v\nactual=%v", errMalformedFilter, err)
+	}
+}
+
+func TestBenchmarkGroup(t *testing.T) {
+	grouped := sampleBench.Group([]string{"y"})
+	expected := map[string]Benchmark{
+		"y=sin(x)": {Name: sampleBench.Name, Results: BenchResults{sampleBench.Results[0], sampleBench.Results[3]}},
+		"y=2x+3":   {Name: sampleBench.Name, Results: BenchResults{sampleBench.Results[1], sampleBench.Results[2]}},
+	}
+	if !reflect.DeepEqual(grouped, expected) {
+		t.Errorf("unexpected grouped benchmarks\nexpected:\n%v\nactual:\n%v", expected, grouped)
+	}
+}
+
+func TestNormalizeProcs(t *testing.T) {
+	procs1 := sampleBench.Rename(sampleBench.Name)
+	procs1.Results = procs1.Results[:2]
+	procs1.Results[0].Inputs.MaxProcs = 1
+
+	normalized := NormalizeProcs([]Benchmark{sampleBench, procs1})
+	if len(normalized) != 2 {
+		t.Fatalf("unexpected number of benchmarks (expected=2, actual=%d)", len(normalized))
+	}
+	for _, bench := range normalized {
+		for _, res := range bench.Results {
+			if res.Inputs.MaxProcs != 1 {
+				t.Errorf("unexpected MaxProcs for %s%s: %d", bench.Name, res.Inputs, res.Inputs.MaxProcs)
+			}
+		}
+	}
+	if sampleBench.Results[0].Inputs.MaxProcs != 4 {
+		t.Errorf("original benchmark unexpectedly modified")
+	}
+
+	common, onlyLeft, onlyRight := normalized[0].CaseOverlap(normalized[1])
+	if len(common) != 2 || len(onlyLeft) != 2 || len(onlyRight) != 0 {
+		t.Errorf("unexpected overlap of normalized benchmarks\ncommon:\n%v\nonly left:\n%v\nonly right:\n%v", common, onlyLeft, onlyRight)
+	}
+}
+
+var summaryLineTests = map[string]struct {
+	bench        Benchmark
+	metric       string
+	expectedLine string
+}{
+	"ns_per_op": {
+		bench:        sampleBench,
+		metric:       "ns/op",
+		expectedLine: "BenchmarkMath: 4 cases, ns/op 13.3–55357 (mean 18948.5)",
+	},
+	"not_measured": {
+		bench:        sampleBench,
+		metric:       "MB/s",
+		expectedLine: "BenchmarkMath: 4 cases, MB/s not measured",
+	},
+	"unknown_metric": {
+		bench:        sampleBench,
+		metric:       "foo/op",
+		expectedLine: "BenchmarkMath: 4 cases, foo/op (unknown metric: foo/op)",
+	},
+}
+
+func TestSummaryLine(t *testing.T) {
+	for testName, testCase := range summaryLineTests {
+		t.Run(testName, func(t *testing.T) {
+			line := testCase.bench.SummaryLine(testCase.metric)
+			if line != testCase.expectedLine {
+				t.Errorf("unexpected summary line\nexpected:\n%s\nactual:\n%s", testCase.expectedLine, line)
+			}
+		})
+	}
+}
+
+var conformsToTests = map[string]struct {
+	bench                 Benchmark
+	schema                []string
+	expectedNonConforming []BenchRes
+	expectedErr           error
+}{
+	"all_conform": {
+		bench:                 Benchmark{Name: sampleBench.Name, Results: sampleBench.Results[2:]},
+		schema:                []string{"end_x", "start_x", "delta", "y"},
+		expectedNonConforming: []BenchRes{},
+	},
+	"missing_var": {
+		bench:                 sampleBench,
+		schema:                []string{"y", "delta", "start_x", "end_x", "abs_val"},
+		expectedNonConforming: []BenchRes{sampleBench.Results[2], sampleBench.Results[3]},
+	},
+	"extra_var": {
+		bench:                 sampleBench,
+		schema:                []string{"y", "delta", "start_x", "end_x"},
+		expectedNonConforming: []BenchRes{sampleBench.Results[0], sampleBench.Results[1]},
+	},
+	"duplicate_schema_name": {
+		bench:       sampleBench,
+		schema:      []string{"y", "y"},
+		expectedErr: errDuplicateVarName,
+	},
+}
+
+func TestConformsTo(t *testing.T) {
+	for testName, testCase := range conformsToTests {
+		t.Run(testName, func(t *testing.T) {
+			nonConforming, err := testCase.bench.ConformsTo(testCase.schema)
+			if err != nil {
+				if !errors.Is(err, testCase.expectedErr) {
+					t.Errorf("unexpected error\nexpected=%s\nactual=%s", testCase.expectedErr, err)
+				}
+				return
+			}
+			if testCase.expectedErr != nil {
+				t.Fatalf("unexpectedly no error")
+			}
+
+			if !reflect.DeepEqual(nonConforming, testCase.expectedNonConforming) {
+				t.Errorf("unexpected non-conforming results\nexpected:\n%v\nactual:\n%v", testCase.expectedNonConforming, nonConforming)
+			}
+		})
+	}
+}
+
+var caseOverlapTests = map[string]struct {
+	left              Benchmark
+	right             Benchmark
+	expectedCommon    []BenchInputs
+	expectedOnlyLeft  []BenchInputs
+	expectedOnlyRight []BenchInputs
+}{
+	"identical": {
+		left:              sampleBench,
+		right:             sampleBench,
+		expectedCommon:    []BenchInputs{sampleBench.Results[0].Inputs, sampleBench.Results[1].Inputs, sampleBench.Results[2].Inputs, sampleBench.Results[3].Inputs},
+		expectedOnlyLeft:  []BenchInputs{},
+		expectedOnlyRight: []BenchInputs{},
+	},
+	"partial_overlap": {
+		left:              Benchmark{Name: sampleBench.Name, Results: sampleBench.Results[:3]},
+		right:             Benchmark{Name: sampleBench.Name, Results: sampleBench.Results[1:]},
+		expectedCommon:    []BenchInputs{sampleBench.Results[1].Inputs, sampleBench.Results[2].Inputs},
+		expectedOnlyLeft:  []BenchInputs{sampleBench.Results[0].Inputs},
+		expectedOnlyRight: []BenchInputs{sampleBench.Results[3].Inputs},
+	},
+	"repeated_inputs": {
+		left:              Benchmark{Name: sampleBench.Name, Results: []BenchRes{sampleBench.Results[0], sampleBench.Results[0]}},
+		right:             Benchmark{Name: sampleBench.Name, Results: []BenchRes{sampleBench.Results[1], sampleBench.Results[1]}},
+		expectedCommon:    []BenchInputs{},
+		expectedOnlyLeft:  []BenchInputs{sampleBench.Results[0].Inputs},
+		expectedOnlyRight: []BenchInputs{sampleBench.Results[1].Inputs},
+	},
+}
+
+func TestCaseOverlap(t *testing.T) {
+	for testName, testCase := range caseOverlapTests {
+		t.Run(testName, func(t *testing.T) {
+			common, onlyLeft, onlyRight := testCase.left.CaseOverlap(testCase.right)
+			if !reflect.DeepEqual(common, testCase.expectedCommon) {
+				t.Errorf("unexpected common inputs\nexpected:\n%v\nactual:\n%v", testCase.expectedCommon, common)
+			}
+			if !reflect.DeepEqual(onlyLeft, testCase.expectedOnlyLeft) {
+				t.Errorf("unexpected left only inputs\nexpected:\n%v\nactual:\n%v", testCase.expectedOnlyLeft, onlyLeft)
+			}
+			if !reflect.DeepEqual(onlyRight, testCase.expectedOnlyRight) {
+				t.Errorf("unexpected right only inputs\nexpected:\n%v\nactual:\n%v", testCase.expectedOnlyRight, onlyRight)
+			}
+		})
+	}
+}
+
+// reorderedWithoutPositions returns a copy of the benchmark with its
+// results reversed and the positions of their inputs cleared.
+func reorderedWithoutPositions(b Benchmark) Benchmark {
+	results := make([]BenchRes, len(b.Results))
+	for i, res := range b.Results {
+		inputs := BenchInputs{MaxProcs: res.Inputs.MaxProcs}
+		for j := len(res.Inputs.VarValues) - 1; j >= 0; j-- {
+			inputs.VarValues = append(inputs.VarValues, BenchVarValue{Name: res.Inputs.VarValues[j].Name, Value: res.Inputs.VarValues[j].Value})
+		}
+		for _, sub := range res.Inputs.Subs {
+			inputs.Subs = append(inputs.Subs, BenchSub{Name: sub.Name})
+		}
+		results[len(results)-1-i] = BenchRes{Inputs: inputs, Outputs: res.Outputs}
+	}
+	return Benchmark{Name: b.Name, Results: results}
+}
+
+var benchmarkEqualTests = map[string]struct {
+	left     Benchmark
+	right    Benchmark
+	expected bool
+}{
+	"identical": {
+		left:     sampleBench,
+		right:    sampleBench,
+		expected: true,
+	},
+	"reordered_without_positions": {
+		left:     sampleBench,
+		right:    reorderedWithoutPositions(sampleBench),
+		expected: true,
+	},
+	"different_name": {
+		left:     sampleBench,
+		right:    sampleBench.Rename("BenchmarkOther"),
+		expected: false,
+	},
+	"different_pkg": {
+		left:     sampleBench,
+		right:    Benchmark{Name: sampleBench.Name, Pkg: "example.com/other", Results: sampleBench.Results},
+		expected: false,
+	},
+	"missing_result": {
+		left:     sampleBench,
+		right:    Benchmark{Name: sampleBench.Name, Results: sampleBench.Results[1:]},
+		expected: false,
+	},
+	"different_outputs": {
+		left:     withMetric(sampleBench, "ns/op", 1, 2, 3, 4),
+		right:    withMetric(sampleBench, "ns/op", 1, 2, 3, 5),
+		expected: false,
+	},
+	"duplicated_result": {
+		left: sampleBench,
+		right: Benchmark{
+			Name:    sampleBench.Name,
+			Results: append([]BenchRes{sampleBench.Results[0]}, sampleBench.Results[:3]...),
+		},
+		expected: false,
+	},
+	"different_value_types": {
+		left: Benchmark{Name: "BenchmarkFoo", Results: BenchResults{
+			{Inputs: BenchInputs{VarValues: []BenchVarValue{{Name: "x", Value: 1, position: 1}}, MaxProcs: 1}},
+		}},
+		right: Benchmark{Name: "BenchmarkFoo", Results: BenchResults{
+			{Inputs: BenchInputs{VarValues: []BenchVarValue{{Name: "x", Value: "1", position: 1}}, MaxProcs: 1}},
+		}},
+		expected: false,
+	},
+	"value_written_differently": {
+		left: Benchmark{Name: "BenchmarkFoo", Results: BenchResults{
+			{Inputs: BenchInputs{VarValues: []BenchVarValue{{Name: "x", Value: 10, position: 1}}, MaxProcs: 1}},
+		}},
+		right: Benchmark{Name: "BenchmarkFoo", Results: BenchResults{
+			{Inputs: BenchInputs{VarValues: []BenchVarValue{{Name: "x", Value: 10, position: 1, text: "010"}}, MaxProcs: 1}},
+		}},
+		expected: true,
+	},
+}
+
+func TestBenchmarkEqual(t *testing.T) {
+	for testName, testCase := range benchmarkEqualTests {
+		t.Run(testName, func(t *testing.T) {
+			if eq := testCase.left.Equal(testCase.right); eq != testCase.expected {
+				t.Errorf("unexpected result (expected=%t, actual=%t)", testCase.expected, eq)
+			}
+			if eq := testCase.right.Equal(testCase.left); eq != testCase.expected {
+				t.Errorf("unexpected result when reversed (expected=%t, actual=%t)", testCase.expected, eq)
+			}
+		})
+	}
+}
+
 func ExampleParseBenchmarks() {
 	r := strings.NewReader(`
 			BenchmarkMath/areaUnder/y=sin(x)/delta=0.001000/start_x=-2/end_x=1/abs_val=true-4         	   21801	     55357 ns/op	       0 B/op	       0 allocs/op
@@ -446,20 +1108,49 @@ func ExampleParseBenchmarks() {
 	}
 	// Output:
 	// bench name: BenchmarkMath
-	// var values = ["y=sin(x)" "delta=0.001000" "start_x=-2" "end_x=1" "abs_val=true"]
+	// var values = ["y=sin(x)" "delta=0.001" "start_x=-2" "end_x=1" "abs_val=true"]
 	// other subs = ["areaUnder"]
 	// ns per op = 55357.00
-	// var values = ["y=2x+3" "delta=1.000000" "start_x=-1" "end_x=2" "abs_val=false"]
+	// var values = ["y=2x+3" "delta=1.0" "start_x=-1" "end_x=2" "abs_val=false"]
 	// other subs = ["areaUnder"]
 	// ns per op = 13.30
-	// var values = ["y=2x+3" "delta=0.001000" "start_x=-2" "end_x=1"]
+	// var values = ["y=2x+3" "delta=0.001" "start_x=-2" "end_x=1"]
 	// other subs = ["max"]
 	// ns per op = 20361.00
-	// var values = ["y=sin(x)" "delta=1.000000" "start_x=-1" "end_x=2"]
+	// var values = ["y=sin(x)" "delta=1.0" "start_x=-1" "end_x=2"]
 	// other subs = ["max"]
 	// ns per op = 62.70
 }
 
+func ExampleParseResultSet() {
+	r := strings.NewReader(`
+goos: linux
+goarch: amd64
+pkg: github.com/ShawnROGrady/benchparse
+cpu: Intel(R) Core(TM) i7-8550U CPU @ 1.80GHz
+BenchmarkMath/max/y=2x+3/delta=0.001000/start_x=-2/end_x=1-4                              	   56282	     20361 ns/op
+BenchmarkMath/max/y=sin(x)/delta=1.000000/start_x=-1/end_x=2-4                            	16381138	        62.7 ns/op
+PASS
+ok  	github.com/ShawnROGrady/benchparse	3.021s
+`)
+	rs, err := ParseResultSet(r)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("goos = %s, goarch = %s\n", rs.Goos, rs.Goarch)
+	fmt.Printf("pkg = %s\n", rs.Pkg)
+	fmt.Printf("cpu = %s\n", rs.CPU)
+	for _, bench := range rs.Benchmarks {
+		fmt.Printf("%s: %d results\n", bench.Name, len(bench.Results))
+	}
+	// Output:
+	// goos = linux, goarch = amd64
+	// pkg = github.com/ShawnROGrady/benchparse
+	// cpu = Intel(R) Core(TM) i7-8550U CPU @ 1.80GHz
+	// BenchmarkMath: 2 results
+}
+
 var parseBenchmarksErr error
 
 func BenchmarkParseBenchmarks(b *testing.B) {
@@ -520,6 +1211,39 @@ func benchmarkParseBenchmarks(b *testing.B, numBenchmarks, casesPerBench int) {
 
 var parseInfoErr error
 
+// BenchmarkParseBenchmarksWithLogs benchmarks parsing output where most
+// lines are logs rather than results.
+func BenchmarkParseBenchmarksWithLogs(b *testing.B) {
+	for _, logsPerCase := range []int{0, 5, 20} {
+		b.Run(fmt.Sprintf("logs_per_case=%d", logsPerCase), func(b *testing.B) {
+			var buf bytes.Buffer
+			for i := 0; i < 25; i++ {
+				bench := &parse.Benchmark{
+					Name:    fmt.Sprintf("BenchmarkMethod/var1=%d/var2=%d", i, i),
+					N:       i,
+					NsPerOp: float64(i),
+				}
+				fmt.Fprintf(&buf, "%s\n--- BENCH: %s\n", bench, bench.Name)
+				for j := 0; j < logsPerCase; j++ {
+					fmt.Fprintf(&buf, "    bench_test.go:%d: log line %d\n", j, j)
+				}
+			}
+			input := buf.Bytes()
+			b.SetBytes(int64(len(input)))
+			b.ResetTimer()
+
+			var err error
+			for i := 0; i < b.N; i++ {
+				_, err = ParseBenchmarks(bytes.NewReader(input))
+				if err != nil {
+					b.Fatalf("unexpected error: %s", err)
+				}
+			}
+			parseBenchmarksErr = err
+		})
+	}
+}
+
 func BenchmarkParseInfo(b *testing.B) {
 	var (
 		dTypes = map[string]func(varName string) string{
@@ -563,3 +1287,449 @@ func BenchmarkParseInfo(b *testing.B) {
 		})
 	}
 }
+
+func TestSelectBenchmarks(t *testing.T) {
+	var (
+		benches = []Benchmark{
+			{Name: "BenchmarkParse"},
+			{Name: "BenchmarkFilter"},
+			{Name: "BenchmarkParseJSON"},
+			{Name: "BenchmarkGroup"},
+		}
+		tests = map[string]struct {
+			pattern  string
+			expected []string
+		}{
+			"glob":           {pattern: "BenchmarkParse*", expected: []string{"BenchmarkParse", "BenchmarkParseJSON"}},
+			"regex":          {pattern: "Filter|Group", expected: []string{"BenchmarkFilter", "BenchmarkGroup"}},
+			"anchored_regex": {pattern: "^BenchmarkParse$", expected: []string{"BenchmarkParse"}},
+			"invalid_regex":  {pattern: "Benchmark[FG]*(", expected: []string{}},
+			"no_match":       {pattern: "Sort", expected: []string{}},
+		}
+	)
+	for testName, testCase := range tests {
+		t.Run(testName, func(t *testing.T) {
+			selected := SelectBenchmarks(benches, testCase.pattern)
+			names := make([]string, len(selected))
+			for i, bench := range selected {
+				names[i] = bench.Name
+			}
+			if !reflect.DeepEqual(names, testCase.expected) {
+				t.Errorf("unexpected benchmarks\nexpected:\n%v\nactual:\n%v", testCase.expected, names)
+			}
+		})
+	}
+}
+
+var parseResultSetTests = map[string]struct {
+	resultSet string
+	parse     func(r io.Reader, opts ...ParseOption) (*ResultSet, error)
+	expected  ResultSet
+}{
+	"with_cpu": {
+		resultSet: `
+			goos: linux
+			goarch: amd64
+			pkg: github.com/ShawnROGrady/mathtest
+			cpu: Intel(R) Core(TM) i7-8565U CPU @ 1.80GHz
+			BenchmarkFoo/bar=1-8   100   12.3 ns/op
+			PASS
+			ok  	github.com/ShawnROGrady/mathtest	1.234s
+			`,
+		parse: ParseResultSet,
+		expected: ResultSet{
+			Goos:    "linux",
+			Goarch:  "amd64",
+			Pkg:     "github.com/ShawnROGrady/mathtest",
+			CPU:     "Intel(R) Core(TM) i7-8565U CPU @ 1.80GHz",
+			Status:  StatusPassed,
+			Elapsed: 1234 * time.Millisecond,
+		},
+	},
+	"failed": {
+		resultSet: `
+			pkg: github.com/ShawnROGrady/mathtest
+			BenchmarkFoo/bar=1-8   100   12.3 ns/op
+			--- FAIL: BenchmarkFoo/bar=2
+			    foo_test.go:12: unexpected result
+			FAIL
+			exit status 1
+			FAIL	github.com/ShawnROGrady/mathtest	0.512s
+			`,
+		parse: ParseResultSet,
+		expected: ResultSet{
+			Pkg:     "github.com/ShawnROGrady/mathtest",
+			Status:  StatusFailed,
+			Elapsed: 512 * time.Millisecond,
+		},
+	},
+	"multiple_packages_one_failed": {
+		resultSet: `
+			pkg: github.com/ShawnROGrady/foo
+			BenchmarkFoo/bar=1-8   100   12.3 ns/op
+			FAIL	github.com/ShawnROGrady/foo	1.5s
+			pkg: github.com/ShawnROGrady/bar
+			BenchmarkBar/bar=1-8   100   12.3 ns/op
+			PASS
+			ok  	github.com/ShawnROGrady/bar	2s
+			`,
+		parse: ParseResultSet,
+		expected: ResultSet{
+			Pkg:     "github.com/ShawnROGrady/foo",
+			Status:  StatusFailed,
+			Elapsed: 3500 * time.Millisecond,
+		},
+	},
+	"without_cpu": {
+		resultSet: parseBenchmarksTests["1_bench_4_cases_benchmem_set"].resultSet,
+		parse:     ParseResultSet,
+		expected: ResultSet{
+			Goos:   "darwin",
+			Goarch: "amd64",
+			Status: StatusPassed,
+		},
+	},
+	"multiple_packages": {
+		resultSet: `
+			goos: linux
+			pkg: github.com/ShawnROGrady/foo
+			BenchmarkFoo/bar=1-8   100   12.3 ns/op
+			goos: linux
+			pkg: github.com/ShawnROGrady/bar
+			BenchmarkBar/bar=1-8   100   12.3 ns/op
+			`,
+		parse: ParseResultSet,
+		expected: ResultSet{
+			Goos: "linux",
+			Pkg:  "github.com/ShawnROGrady/foo",
+		},
+	},
+	"json": {
+		resultSet: `{"Action":"output","Package":"github.com/ShawnROGrady/mathtest","Output":"goos: linux\n"}
+{"Action":"output","Package":"github.com/ShawnROGrady/mathtest","Output":"goarch: arm64\n"}
+{"Action":"output","Package":"github.com/ShawnROGrady/mathtest","Output":"pkg: github.com/ShawnROGrady/mathtest\n"}
+{"Action":"output","Package":"github.com/ShawnROGrady/mathtest","Output":"cpu: Apple M1\n"}
+{"Action":"output","Package":"github.com/ShawnROGrady/mathtest","Output":"BenchmarkFoo/bar=1-8   \t     100\t        12.3 ns/op\n"}
+{"Action":"output","Package":"github.com/ShawnROGrady/mathtest","Output":"PASS\n"}
+{"Action":"output","Package":"github.com/ShawnROGrady/mathtest","Output":"ok  \tgithub.com/ShawnROGrady/mathtest\t0.100s\n"}`,
+		parse: ParseResultSetFromJSON,
+		expected: ResultSet{
+			Goos:    "linux",
+			Goarch:  "arm64",
+			Pkg:     "github.com/ShawnROGrady/mathtest",
+			CPU:     "Apple M1",
+			Status:  StatusPassed,
+			Elapsed: 100 * time.Millisecond,
+		},
+	},
+}
+
+func TestParseResultSet(t *testing.T) {
+	for testName, testCase := range parseResultSetTests {
+		t.Run(testName, func(t *testing.T) {
+			rs, err := testCase.parse(strings.NewReader(testCase.resultSet))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(rs.Benchmarks) == 0 {
+				t.Errorf("unexpectedly no benchmarks")
+			}
+			metadata := *rs
+			metadata.Benchmarks = nil
+			if !reflect.DeepEqual(metadata, testCase.expected) {
+				t.Errorf("unexpected metadata\nexpected:\n%+v\nactual:\n%+v", testCase.expected, metadata)
+			}
+		})
+	}
+}
+
+func TestParsePackagesFromJSON(t *testing.T) {
+	// events from the two packages are interleaved, with results of
+	// the same benchmark name in both packages, while example.com/baz
+	// only runs tests
+	input := `{"Action":"output","Output":"go: downloading example.com/dep v1.0.0\n"}
+{"Action":"run","Package":"example.com/baz","Test":"TestBaz"}
+{"Action":"output","Package":"example.com/baz","Test":"TestBaz","Output":"=== RUN   TestBaz\n"}
+{"Action":"output","Package":"example.com/baz","Output":"PASS\n"}
+{"Action":"output","Package":"example.com/baz","Output":"ok  \texample.com/baz\t0.1s\n"}
+{"Action":"output","Package":"example.com/foo","Output":"goos: linux\n"}
+{"Action":"output","Package":"example.com/bar","Output":"goos: linux\n"}
+{"Action":"output","Package":"example.com/foo","Output":"pkg: example.com/foo\n"}
+{"Action":"output","Package":"example.com/bar","Output":"pkg: example.com/bar\n"}
+{"Action":"output","Package":"example.com/foo","Output":"BenchmarkParse\n"}
+{"Action":"output","Package":"example.com/bar","Output":"BenchmarkParse\n"}
+{"Action":"output","Package":"example.com/foo","Output":"BenchmarkParse/size=1\n"}
+{"Action":"output","Package":"example.com/bar","Output":"BenchmarkParse/size=1-4   \t     100\t        20 ns/op\n"}
+{"Action":"output","Package":"example.com/foo","Output":"BenchmarkParse/size=1-4   \t     100\t        10 ns/op\n"}
+{"Action":"output","Package":"example.com/bar","Output":"BenchmarkBar-4   \t     100\t        30 ns/op\n"}
+{"Action":"output","Package":"example.com/foo","Output":"PASS\n"}
+{"Action":"pass","Package":"example.com/foo","Elapsed":1.2}
+{"Action":"output","Package":"example.com/bar","Output":"PASS\n"}
+{"Action":"pass","Package":"example.com/bar","Elapsed":1.3}`
+
+	sets, err := ParsePackagesFromJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expectedNsPerOp := map[string]map[string]float64{
+		"example.com/foo": {"BenchmarkParse": 10},
+		"example.com/bar": {"BenchmarkParse": 20, "BenchmarkBar": 30},
+	}
+	if len(sets) != len(expectedNsPerOp) {
+		t.Fatalf("unexpected number of packages (expected=%d, actual=%d)", len(expectedNsPerOp), len(sets))
+	}
+	for pkg, expected := range expectedNsPerOp {
+		rs, ok := sets[pkg]
+		if !ok {
+			t.Fatalf("no result set for package %s", pkg)
+		}
+		if rs.Pkg != pkg || rs.Goos != "linux" {
+			t.Errorf("unexpected metadata for package %s: %+v", pkg, rs)
+		}
+		actual := map[string]float64{}
+		for _, bench := range rs.Benchmarks {
+			for _, res := range bench.Results {
+				v, err := res.Outputs.GetNsPerOp()
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				actual[bench.Name] = v
+			}
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected results for package %s\nexpected:\n%v\nactual:\n%v", pkg, expected, actual)
+		}
+	}
+}
+
+func TestParsePackagesFromJSONErr(t *testing.T) {
+	input := `{"Action":"output","Package":"example.com/foo","Output":"goos: linux\n"}
+{"Action":"output","Package":"example.com/foo","Output":"BenchmarkFoo-4 100 10 ns/op\n"}
+{"Action":"output","Package":"example.com/foo"`
+
+	_, err := ParsePackagesFromJSON(strings.NewReader(input))
+	if err == nil {
+		t.Fatalf("unexpectedly no error")
+	}
+	if !strings.HasPrefix(err.Error(), "line 3: ") {
+		t.Errorf("error missing line number: %s", err)
+	}
+}
+
+func TestByProcs(t *testing.T) {
+	input := `
+		BenchmarkFoo/size=1     100   40 ns/op
+		BenchmarkFoo/size=1-2   100   20 ns/op
+		BenchmarkFoo/size=1-4   100   10 ns/op
+		BenchmarkFoo/size=2     100   80 ns/op
+		BenchmarkFoo/size=2-2   100   40 ns/op
+		BenchmarkFoo/size=2-4   100   20 ns/op
+		`
+	benches, err := ParseBenchmarks(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var (
+		results  = benches[0].Results
+		grouped  = benches[0].ByProcs()
+		expected = GroupedResults{
+			"1": BenchResults{results[0], results[3]},
+			"2": BenchResults{results[1], results[4]},
+			"4": BenchResults{results[2], results[5]},
+		}
+	)
+	if !reflect.DeepEqual(grouped, expected) {
+		t.Errorf("unexpected grouped results\nexpected:\n%v\nactual:\n%v", expected, grouped)
+	}
+}
+
+var malformedProcsTests = map[string]struct {
+	line             string
+	expectedName     string
+	expectedInputs   string
+	expectedMaxProcs int
+}{
+	"trailing_x": {
+		line:             "BenchmarkFoo/bar=1-4x   100   10 ns/op",
+		expectedName:     "BenchmarkFoo",
+		expectedInputs:   "/bar=1-4x",
+		expectedMaxProcs: 1,
+	},
+	"trailing_dash": {
+		line:             "BenchmarkFoo/bar-   100   10 ns/op",
+		expectedName:     "BenchmarkFoo",
+		expectedInputs:   "/bar-",
+		expectedMaxProcs: 1,
+	},
+	"top_level_trailing_dash": {
+		line:             "BenchmarkFoo-   100   10 ns/op",
+		expectedName:     "BenchmarkFoo-",
+		expectedInputs:   "",
+		expectedMaxProcs: 1,
+	},
+	"overflow": {
+		line:             "BenchmarkFoo/bar-99999999999999999999   100   10 ns/op",
+		expectedName:     "BenchmarkFoo",
+		expectedInputs:   "/bar-99999999999999999999",
+		expectedMaxProcs: 1,
+	},
+	"zero": {
+		line:             "BenchmarkFoo/bar-0   100   10 ns/op",
+		expectedName:     "BenchmarkFoo",
+		expectedInputs:   "/bar-0",
+		expectedMaxProcs: 1,
+	},
+	"valid": {
+		line:             "BenchmarkFoo/bar-16   100   10 ns/op",
+		expectedName:     "BenchmarkFoo",
+		expectedInputs:   "/bar-16",
+		expectedMaxProcs: 16,
+	},
+}
+
+func TestParseMalformedProcs(t *testing.T) {
+	for testName, testCase := range malformedProcsTests {
+		t.Run(testName, func(t *testing.T) {
+			input := testCase.line + "\nBenchmarkOther-4   100   10 ns/op"
+			benches, err := ParseBenchmarks(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(benches) != 2 {
+				t.Fatalf("unexpected number of benchmarks (expected=2, actual=%d)", len(benches))
+			}
+			selected := SelectBenchmarks(benches, "^"+regexp.QuoteMeta(testCase.expectedName)+"$")
+			if len(selected) != 1 {
+				t.Fatalf("no benchmark named %s in %v", testCase.expectedName, benches)
+			}
+			res := selected[0].Results[0]
+			if res.Inputs.String() != testCase.expectedInputs {
+				t.Errorf("unexpected inputs (expected=%q, actual=%q)", testCase.expectedInputs, res.Inputs)
+			}
+			if res.Inputs.MaxProcs != testCase.expectedMaxProcs {
+				t.Errorf("unexpected max procs (expected=%d, actual=%d)", testCase.expectedMaxProcs, res.Inputs.MaxProcs)
+			}
+		})
+	}
+}
+
+func TestVarCoverageDiff(t *testing.T) {
+	parseBench := func(input string) Benchmark {
+		t.Helper()
+		benches, err := ParseBenchmarks(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return benches[0]
+	}
+	var (
+		old = parseBench(`
+			BenchmarkFoo/size=1/mode=fast/legacy=true-4   100   10 ns/op
+			BenchmarkFoo/size=2/mode=fast/legacy=true-4   100   10 ns/op
+			BenchmarkFoo/size=4/mode=slow/legacy=true-4   100   10 ns/op
+			`)
+		new = parseBench(`
+			BenchmarkFoo/size=2/mode=fast/workers=1-4   100   10 ns/op
+			BenchmarkFoo/size=4/mode=slow/workers=2-4   100   10 ns/op
+			BenchmarkFoo/size=8/mode=slow/workers=2-4   100   10 ns/op
+			BenchmarkFoo/size=16/mode=slow/workers=2-4   100   10 ns/op
+			`)
+		expectedAdded   = []string{"workers"}
+		expectedRemoved = []string{"legacy"}
+		expectedChanged = map[string]ValueDiff{
+			"size": {Added: []interface{}{8, 16}, Removed: []interface{}{1}},
+		}
+	)
+
+	added, removed, changed := VarCoverageDiff(old, new)
+	if !reflect.DeepEqual(added, expectedAdded) {
+		t.Errorf("unexpected added vars\nexpected:\n%v\nactual:\n%v", expectedAdded, added)
+	}
+	if !reflect.DeepEqual(removed, expectedRemoved) {
+		t.Errorf("unexpected removed vars\nexpected:\n%v\nactual:\n%v", expectedRemoved, removed)
+	}
+	if !reflect.DeepEqual(changed, expectedChanged) {
+		t.Errorf("unexpected changed values\nexpected:\n%v\nactual:\n%v", expectedChanged, changed)
+	}
+
+	added, removed, changed = VarCoverageDiff(old, old)
+	if len(added) != 0 || len(removed) != 0 || len(changed) != 0 {
+		t.Errorf("unexpected diff of benchmark with itself: %v %v %v", added, removed, changed)
+	}
+
+	// NaN != NaN, but the values are still the same
+	nan := parseBench("BenchmarkFoo/delta=NaN-4   100   10 ns/op")
+	added, removed, changed = VarCoverageDiff(nan, nan)
+	if len(added) != 0 || len(removed) != 0 || len(changed) != 0 {
+		t.Errorf("unexpected diff of benchmark with NaN value with itself: %v %v %v", added, removed, changed)
+	}
+}
+
+func TestParseIgnoresDiagnosticLines(t *testing.T) {
+	input := `
+testing: warning: no tests to run
+goos: linux
+goarch: amd64
+pkg: github.com/ShawnROGrady/mathtest
+=== RUN   BenchmarkFoo
+BenchmarkFoo
+BenchmarkFoo/bar=1
+BenchmarkFoo/bar=1-4   	     100	        10 ns/op
+--- BENCH: BenchmarkFoo/bar=1-4
+    foo_test.go:12: Benchmark ran 100 iterations
+    foo_test.go:13: BenchmarkFoo 100 12 ns/op
+--- FAIL: BenchmarkBar
+    bar_test.go:20: setup failed
+--- SKIP: BenchmarkBaz
+PASS
+FAIL
+ok  	github.com/ShawnROGrady/mathtest	1.234s
+FAIL	github.com/ShawnROGrady/mathtest	1.234s
+`
+	benches, err := ParseBenchmarks(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(benches) != 1 {
+		t.Fatalf("unexpected number of benchmarks (expected=1, actual=%d)\n%v", len(benches), benches)
+	}
+	if benches[0].Name != "BenchmarkFoo" || len(benches[0].Results) != 1 {
+		t.Errorf("unexpected benchmark:\n%v", benches[0])
+	}
+}
+
+func TestParseBenchmarksStrict(t *testing.T) {
+	valid := `
+goos: linux
+BenchmarkFoo
+BenchmarkFoo/bar=1-4   	     100	        10 ns/op
+--- BENCH: BenchmarkFoo/bar=1-4
+    foo_test.go:12: Benchmark ran 100 iterations
+PASS
+`
+	benches, err := ParseBenchmarksStrict(strings.NewReader(valid))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(benches) != 1 || len(benches[0].Results) != 1 {
+		t.Errorf("unexpected benchmarks:\n%v", benches)
+	}
+
+	malformed := `
+goos: linux
+BenchmarkFoo/bar=1-4   	     100	        10 ns/op
+BenchmarkFoo/bar=2-4   	     1O0	        20 ns/op
+`
+	_, err = ParseBenchmarksStrict(strings.NewReader(malformed))
+	if !errors.Is(err, errMalformedResult) {
+		t.Fatalf("unexpected error\nexpected=%v\nactual=%v", errMalformedResult, err)
+	}
+	if !strings.Contains(err.Error(), "line 4") || !strings.Contains(err.Error(), "BenchmarkFoo/bar=2-4") {
+		t.Errorf("error missing line number or text: %s", err)
+	}
+
+	if _, err := ParseBenchmarks(strings.NewReader(malformed)); err != nil {
+		t.Errorf("unexpected error without strict parsing: %s", err)
+	}
+}
@@ -0,0 +1,53 @@
+package benchparse
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteBenchstat writes the benchmarks to w in the text format of
+// 'go test -bench', as accepted by benchstat, with one line per result.
+// Unlike Benchmark.String, metrics are written with full precision so
+// the results can be compared by benchstat after being filtered or
+// grouped without losing information.
+func WriteBenchstat(w io.Writer, benches []Benchmark) error {
+	bw := bufio.NewWriter(w)
+	writeBenchstatResults(bw, benches)
+	return bw.Flush()
+}
+
+// WriteBenchstat writes the result set to w like WriteBenchstat, preceded
+// by the metadata lines (e.g. 'goos: linux') benchstat uses to label the
+// results. Metadata which is empty is omitted.
+func (rs *ResultSet) WriteBenchstat(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	metadata := []struct{ key, value string }{
+		{key: "goos", value: rs.Goos},
+		{key: "goarch", value: rs.Goarch},
+		{key: "pkg", value: rs.Pkg},
+		{key: "cpu", value: rs.CPU},
+	}
+	for _, m := range metadata {
+		if m.value != "" {
+			fmt.Fprintf(bw, "%s: %s\n", m.key, m.value)
+		}
+	}
+	writeBenchstatResults(bw, rs.Benchmarks)
+	return bw.Flush()
+}
+
+// writeBenchstatResults writes a line for each result of the benchmarks.
+// Errors are reported by the bufio.Writer once flushed.
+func writeBenchstatResults(bw *bufio.Writer, benches []Benchmark) {
+	for _, bench := range benches {
+		for _, res := range bench.Results {
+			fmt.Fprintf(bw, "%s%s\t%d", bench.Name, res.Inputs, res.Outputs.GetIterations())
+			for _, m := range measuredMetrics(res.Outputs) {
+				fmt.Fprintf(bw, "\t%s %s", strconv.FormatFloat(m.value, 'f', -1, 64), m.metric)
+			}
+			bw.WriteString("\n")
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package benchparse
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteBenchstat(t *testing.T) {
+	input := `goos: linux
+goarch: amd64
+pkg: github.com/ShawnROGrady/mathtest
+BenchmarkFoo/size=1-4	100	0.123 ns/op	1.5 MB/s	16 B/op	1 allocs/op	3.25 hits/op
+BenchmarkFoo/size=2-4	50	40 ns/op
+BenchmarkBar/areaUnder-4	10	12345.678 ns/op
+PASS
+`
+	rs, err := ParseResultSet(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := rs.WriteBenchstat(&buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := strings.TrimSuffix(input, "PASS\n")
+	if buf.String() != expected {
+		t.Errorf("unexpected output\nexpected:\n%s\nactual:\n%s", expected, buf.String())
+	}
+
+	reparsed, err := ParseResultSet(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i, bench := range reparsed.Benchmarks {
+		if !bench.Equal(rs.Benchmarks[i]) {
+			t.Errorf("benchmark not preserved\noriginal:\n%s\nreparsed:\n%s", rs.Benchmarks[i], bench)
+		}
+	}
+
+	buf.Reset()
+	if err := WriteBenchstat(&buf, rs.Benchmarks[1:]); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "BenchmarkBar/areaUnder-4\t10\t12345.678 ns/op\n"; buf.String() != expected {
+		t.Errorf("unexpected output\nexpected:\n%s\nactual:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,69 @@
+package benchparse
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes, parsed from an input variable value with
+// a size suffix such as 'size=4KB' or 'buf=1MiB'. Since it is an integer,
+// it can be compared against other sizes and numbers, for example
+// filtering by 'size>1024'.
+type ByteSize int64
+
+// byteSizeUnits are the recognized size suffixes, in decreasing order
+// of size so String uses the largest unit which evenly divides a size.
+var byteSizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{suffix: "TiB", size: 1 << 40},
+	{suffix: "TB", size: 1e12},
+	{suffix: "GiB", size: 1 << 30},
+	{suffix: "GB", size: 1e9},
+	{suffix: "MiB", size: 1 << 20},
+	{suffix: "MB", size: 1e6},
+	{suffix: "KiB", size: 1 << 10},
+	{suffix: "KB", size: 1e3},
+	{suffix: "B", size: 1},
+}
+
+// String returns the size using the largest unit which evenly divides
+// it, e.g. '4KB' for 4000 bytes and '4KiB' for 4096 bytes. This may
+// differ from the original input, such as '1024KB' becoming '1000KiB',
+// but is always parsed as the same size. Variables parsed from a
+// benchmark name keep the original input, see BenchVarValue.String.
+func (b ByteSize) String() string {
+	for _, unit := range byteSizeUnits {
+		if b != 0 && int64(b)%unit.size == 0 {
+			return strconv.FormatInt(int64(b)/unit.size, 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
+
+var errInvalidByteSize = errors.New("invalid byte size")
+
+// parseByteSize parses a non-negative size with one of the decimal
+// (KB, MB, GB, TB) or binary (KiB, MiB, GiB, TiB) suffixes or a 'B'
+// suffix for bytes. Fractional sizes (e.g. '1.5KB') are allowed as long
+// as they are a whole number of bytes.
+func parseByteSize(s string) (ByteSize, error) {
+	for _, unit := range byteSizeUnits {
+		if !strings.HasSuffix(s, unit.suffix) {
+			continue
+		}
+		n, err := strconv.ParseFloat(strings.TrimSuffix(s, unit.suffix), 64)
+		if err != nil {
+			return 0, errInvalidByteSize
+		}
+		size := n * float64(unit.size)
+		if size < 0 || size != math.Trunc(size) || size >= math.MaxInt64 {
+			return 0, errInvalidByteSize
+		}
+		return ByteSize(size), nil
+	}
+	return 0, errInvalidByteSize
+}
@@ -0,0 +1,110 @@
+package benchparse
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var parseByteSizeTests = map[string]struct {
+	expectedSize ByteSize
+	expectedErr  error
+}{
+	"512B":       {expectedSize: 512},
+	"4KB":        {expectedSize: 4000},
+	"4KiB":       {expectedSize: 4096},
+	"1MB":        {expectedSize: 1000000},
+	"1MiB":       {expectedSize: 1 << 20},
+	"2GB":        {expectedSize: 2e9},
+	"2GiB":       {expectedSize: 2 << 30},
+	"1TiB":       {expectedSize: 1 << 40},
+	"1.5KiB":     {expectedSize: 1536},
+	"0KB":        {expectedSize: 0},
+	"1.0001B":    {expectedErr: errInvalidByteSize},
+	"-1KB":       {expectedErr: errInvalidByteSize},
+	"KB":         {expectedErr: errInvalidByteSize},
+	"4kb":        {expectedErr: errInvalidByteSize},
+	"4":          {expectedErr: errInvalidByteSize},
+	"10000000TB": {expectedErr: errInvalidByteSize},
+}
+
+func TestParseByteSize(t *testing.T) {
+	for testInput, testCase := range parseByteSizeTests {
+		t.Run(testInput, func(t *testing.T) {
+			size, err := parseByteSize(testInput)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if size != testCase.expectedSize {
+				t.Errorf("unexpected size (expected=%d, actual=%d)", testCase.expectedSize, size)
+			}
+		})
+	}
+}
+
+var byteSizeStringTests = map[string]struct {
+	size           ByteSize
+	expectedString string
+}{
+	"zero":          {size: 0, expectedString: "0B"},
+	"bytes":         {size: 123, expectedString: "123B"},
+	"decimal":       {size: 4000, expectedString: "4KB"},
+	"binary":        {size: 4096, expectedString: "4KiB"},
+	"prefer_larger": {size: 1024000, expectedString: "1000KiB"},
+	"mebibytes":     {size: 3 << 20, expectedString: "3MiB"},
+	"gigabytes":     {size: 5e9, expectedString: "5GB"},
+}
+
+func TestByteSizeString(t *testing.T) {
+	for testName, testCase := range byteSizeStringTests {
+		t.Run(testName, func(t *testing.T) {
+			s := testCase.size.String()
+			if s != testCase.expectedString {
+				t.Errorf("unexpected string (expected=%s, actual=%s)", testCase.expectedString, s)
+			}
+			reparsed, err := parseByteSize(s)
+			if err != nil {
+				t.Fatalf("unexpected error parsing %s: %s", s, err)
+			}
+			if reparsed != testCase.size {
+				t.Errorf("unexpected size after round trip (expected=%d, actual=%d)", testCase.size, reparsed)
+			}
+		})
+	}
+}
+
+func TestByteSizeValues(t *testing.T) {
+	benches, err := ParseBenchmarks(strings.NewReader(`
+BenchmarkCopy/size=512B-4 100 10 ns/op
+BenchmarkCopy/size=4KB-4 100 20 ns/op
+BenchmarkCopy/size=1MiB-4 100 30 ns/op
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	results := benches[0].Results
+
+	if v := results[1].Inputs.VarValues[0].Value; v != ByteSize(4000) {
+		t.Errorf("unexpected value (expected=%#v, actual=%#v)", ByteSize(4000), v)
+	}
+	if s := results[1].Inputs.VarValues[0].String(); s != "size=4KB" {
+		t.Errorf("unexpected string (expected=size=4KB, actual=%s)", s)
+	}
+
+	filters := map[string]BenchResults{
+		"size>1024":   results[1:],
+		"size<=4KiB":  results[:2],
+		"size==1MiB":  results[2:],
+		"size!=4000B": BenchResults{results[0], results[2]},
+	}
+	for expr, expected := range filters {
+		filtered, err := results.Filter(expr)
+		if err != nil {
+			t.Fatalf("unexpected error filtering by %s: %s", expr, err)
+		}
+		if !reflect.DeepEqual(filtered, expected) {
+			t.Errorf("unexpected results filtering by %s\nexpected:\n%v\nactual:\n%v", expr, expected, filtered)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package benchparse
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+// CachingParser memoizes the benchmarks parsed from an input, keyed by
+// a hash of the input's content. This is useful when the same result
+// set is parsed repeatedly, for example by a server which reloads an
+// archived benchmark file.
+//
+// Concurrent callers parsing the same content share a single parse.
+// Once the cache holds more than its max size the least recently used
+// entry is evicted. Failed parses are not cached.
+//
+// The returned benchmarks are shared between callers, so they should
+// not be modified.
+type CachingParser struct {
+	parse   func(r io.Reader) ([]Benchmark, error)
+	maxSize int
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*cacheEntry
+	lru     *list.List // of [sha256.Size]byte, most recently used at front
+}
+
+type cacheEntry struct {
+	done    chan struct{} // closed once the parse completes
+	benches []Benchmark
+	err     error
+	elem    *list.Element
+}
+
+// NewCachingParser returns a CachingParser which uses the provided parse
+// function (e.g. a function wrapping ParseBenchmarks or
+// ParseBenchmarksFromJSON) and caches the results of at most maxSize
+// distinct inputs. A maxSize less than 1 is treated as 1, so that the
+// most recent input is always cached and concurrent callers parsing it
+// still share a single parse.
+func NewCachingParser(parse func(r io.Reader) ([]Benchmark, error), maxSize int) *CachingParser {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	return &CachingParser{
+		parse:   parse,
+		maxSize: maxSize,
+		entries: map[[sha256.Size]byte]*cacheEntry{},
+		lru:     list.New(),
+	}
+}
+
+// Parse returns the benchmarks parsed from r, using the cached
+// benchmarks if the same content has already been parsed.
+func (c *CachingParser) Parse(r io.Reader) ([]Benchmark, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+
+	c.mu.Lock()
+	if e, ok := c.entries[sum]; ok {
+		c.lru.MoveToFront(e.elem)
+		c.mu.Unlock()
+		<-e.done
+		return e.benches, e.err
+	}
+	e := &cacheEntry{done: make(chan struct{})}
+	e.elem = c.lru.PushFront(sum)
+	c.entries[sum] = e
+	for c.lru.Len() > c.maxSize && c.lru.Back() != e.elem {
+		c.remove(c.lru.Back().Value.([sha256.Size]byte))
+	}
+	c.mu.Unlock()
+
+	e.benches, e.err = c.parse(bytes.NewReader(data))
+	close(e.done)
+
+	if e.err != nil {
+		c.mu.Lock()
+		if c.entries[sum] == e {
+			c.remove(sum)
+		}
+		c.mu.Unlock()
+	}
+	return e.benches, e.err
+}
+
+// Len returns the number of cached inputs.
+func (c *CachingParser) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// remove must be called with c.mu held.
+func (c *CachingParser) remove(sum [sha256.Size]byte) {
+	e, ok := c.entries[sum]
+	if !ok {
+		return
+	}
+	c.lru.Remove(e.elem)
+	delete(c.entries, sum)
+}
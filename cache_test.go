@@ -0,0 +1,150 @@
+package benchparse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type countingParser struct {
+	calls int32
+	wait  chan struct{}
+}
+
+func (c *countingParser) parse(r io.Reader) ([]Benchmark, error) {
+	atomic.AddInt32(&c.calls, 1)
+	if c.wait != nil {
+		<-c.wait
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty input")
+	}
+	return ParseBenchmarks(strings.NewReader(string(data)))
+}
+
+func TestCachingParserConcurrent(t *testing.T) {
+	var (
+		p       = &countingParser{wait: make(chan struct{})}
+		c       = NewCachingParser(p.parse, 2)
+		input   = sampleBench.String()
+		callers = 10
+		wg      sync.WaitGroup
+		results = make([][]Benchmark, callers)
+		errs    = make([]error, callers)
+	)
+
+	wg.Add(callers)
+	for i := 0; i < callers; i++ {
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = c.Parse(strings.NewReader(input))
+		}(i)
+	}
+	close(p.wait)
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&p.calls); calls != 1 {
+		t.Errorf("unexpected number of parses (expected=1, actual=%d)", calls)
+	}
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("unexpected error: %s", errs[i])
+		}
+		if !reflect.DeepEqual(results[i], results[0]) {
+			t.Errorf("unexpected benchmarks for caller %d\nexpected:\n%v\nactual:\n%v", i, results[0], results[i])
+		}
+	}
+	if len(results[0]) != 1 {
+		t.Fatalf("unexpected number of benchmarks (expected=1, actual=%d)", len(results[0]))
+	}
+	testBenchmarkEqual(t, sampleBench, results[0][0])
+}
+
+func TestCachingParserEviction(t *testing.T) {
+	var (
+		p      = &countingParser{}
+		c      = NewCachingParser(p.parse, 2)
+		inputs = make([]string, 3)
+	)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("BenchmarkFoo/var=%d 100 10 ns/op", i)
+	}
+
+	parse := func(input string) {
+		t.Helper()
+		if _, err := c.Parse(strings.NewReader(input)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	parse(inputs[0])
+	parse(inputs[1])
+	parse(inputs[0]) // inputs[1] is now least recently used
+	parse(inputs[2]) // evicts inputs[1]
+	if calls := atomic.LoadInt32(&p.calls); calls != 3 {
+		t.Errorf("unexpected number of parses (expected=3, actual=%d)", calls)
+	}
+	if c.Len() != 2 {
+		t.Errorf("unexpected cache size (expected=2, actual=%d)", c.Len())
+	}
+
+	parse(inputs[0])
+	if calls := atomic.LoadInt32(&p.calls); calls != 3 {
+		t.Errorf("unexpected number of parses after cache hit (expected=3, actual=%d)", calls)
+	}
+	parse(inputs[1])
+	if calls := atomic.LoadInt32(&p.calls); calls != 4 {
+		t.Errorf("unexpected number of parses after eviction (expected=4, actual=%d)", calls)
+	}
+}
+
+func TestCachingParserMinSize(t *testing.T) {
+	for _, maxSize := range []int{0, -1} {
+		t.Run(fmt.Sprintf("max_size=%d", maxSize), func(t *testing.T) {
+			var (
+				p      = &countingParser{}
+				c      = NewCachingParser(p.parse, maxSize)
+				inputs = []string{"BenchmarkFoo/var=0 100 10 ns/op", "BenchmarkFoo/var=1 100 10 ns/op"}
+			)
+			for _, input := range []string{inputs[0], inputs[0], inputs[1]} {
+				if _, err := c.Parse(strings.NewReader(input)); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			}
+			if calls := atomic.LoadInt32(&p.calls); calls != 2 {
+				t.Errorf("unexpected number of parses (expected=2, actual=%d)", calls)
+			}
+			if c.Len() != 1 {
+				t.Errorf("unexpected cache size (expected=1, actual=%d)", c.Len())
+			}
+		})
+	}
+}
+
+func TestCachingParserErrNotCached(t *testing.T) {
+	var (
+		p = &countingParser{}
+		c = NewCachingParser(p.parse, 2)
+	)
+	for i := 0; i < 2; i++ {
+		if _, err := c.Parse(strings.NewReader("")); err == nil {
+			t.Errorf("unexpectedly no error")
+		}
+	}
+	if calls := atomic.LoadInt32(&p.calls); calls != 2 {
+		t.Errorf("unexpected number of parses (expected=2, actual=%d)", calls)
+	}
+	if c.Len() != 0 {
+		t.Errorf("unexpected cache size (expected=0, actual=%d)", c.Len())
+	}
+}
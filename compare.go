@@ -3,6 +3,10 @@ package benchparse
 import (
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -17,6 +21,11 @@ const (
 	Gt Comparison = ">"
 	Le Comparison = "<="
 	Ge Comparison = ">="
+
+	// Match and NotMatch test the string value of a variable against
+	// a regular expression, for example 'y=~^sin' or 'y!~(?i)COS'.
+	Match    Comparison = "=~"
+	NotMatch Comparison = "!~"
 )
 
 func (c Comparison) description() string {
@@ -33,18 +42,41 @@ func (c Comparison) description() string {
 		return "le"
 	case Ge:
 		return "ge"
+	case Match:
+		return "match"
+	case NotMatch:
+		return "nomatch"
 	default:
 		return ""
 	}
 }
 
-// Possible comparison errors.
+// Possible comparison errors. Errors returned when parsing or applying
+// a filter wrap these, so they can be checked with errors.Is.
+var (
+	// ErrOperationNotDefined indicates that the comparison is not
+	// defined for the values, e.g. '<' for bools.
+	ErrOperationNotDefined = errors.New("operation not defined for values")
+	// ErrNonComparable indicates that the values have incompatible
+	// types, e.g. a string and an int.
+	ErrNonComparable = errors.New("values cannot be compared")
+	// ErrDifferentNames indicates that the values are of different
+	// variables.
+	ErrDifferentNames = errors.New("variables have different names")
+	// ErrInvalidOperation indicates an unknown Comparison.
+	ErrInvalidOperation = errors.New("invalid comparison operation")
+	// ErrMalformedFilter indicates that a filter expression could not
+	// be parsed.
+	ErrMalformedFilter = errors.New("filter expression not of form 'var_name==var_value'")
+)
+
+// Unexported aliases of the comparison errors for internal use.
 var (
-	errOperationNotDefined = errors.New("operation not defined for values")
-	errNonComparable       = errors.New("values cannot be compared")
-	errDifferentNames      = errors.New("variables have different names")
-	errInvalidOperation    = errors.New("invalid comparison operation")
-	errMalformedFilter     = errors.New("filter expression not of form 'var_name==var_value'")
+	errOperationNotDefined = ErrOperationNotDefined
+	errNonComparable       = ErrNonComparable
+	errDifferentNames      = ErrDifferentNames
+	errInvalidOperation    = ErrInvalidOperation
+	errMalformedFilter     = ErrMalformedFilter
 )
 
 type compareErr struct {
@@ -108,21 +140,166 @@ func (c Comparison) compare(v1, v2 BenchVarValue) (bool, error) {
 			return false, compareErr{val1: v1, val2: v2, comparison: c, err: err}
 		}
 		return !less, nil
+	case Match:
+		match, err := v1.match(v2)
+		if err != nil {
+			return false, compareErr{val1: v1, val2: v2, comparison: c, err: err}
+		}
+		return match, nil
+	case NotMatch:
+		match, err := v1.match(v2)
+		if err != nil {
+			return false, compareErr{val1: v1, val2: v2, comparison: c, err: err}
+		}
+		return !match, nil
 	default:
 		return false, compareErr{val1: v1, val2: v2, comparison: c, err: errInvalidOperation}
 	}
 }
 
+// CompareFloat reports whether a and b satisfy the comparison, e.g.
+// Gt.CompareFloat(a, b) reports whether a > b. This is intended for
+// checking measured outputs against thresholds. Since Eq and Ne compare
+// the floats exactly, CompareFloatEps should usually be preferred for
+// those. Match and NotMatch are not defined for floats.
+func (c Comparison) CompareFloat(a, b float64) (bool, error) {
+	return c.CompareFloatEps(a, b, 0)
+}
+
+// CompareFloatEps is like CompareFloat, but treats a and b as equal if
+// they differ by at most eps. Lt and Gt only hold if a and b are not
+// equal in this sense, while Le and Ge hold if they are.
+func (c Comparison) CompareFloatEps(a, b, eps float64) (bool, error) {
+	eq := math.Abs(a-b) <= eps
+	switch c {
+	case Eq:
+		return eq, nil
+	case Ne:
+		return !eq, nil
+	case Lt:
+		return !eq && a < b, nil
+	case Gt:
+		return !eq && a > b, nil
+	case Le:
+		return eq || a < b, nil
+	case Ge:
+		return eq || a > b, nil
+	case Match, NotMatch:
+		return false, fmt.Errorf("cannot evaluate (%g)%s(%g): %w", a, c, b, errOperationNotDefined)
+	default:
+		return false, fmt.Errorf("cannot evaluate (%g)%s(%g): %w", a, c, b, errInvalidOperation)
+	}
+}
+
+// match reports whether the value of b, which must be a string, matches
+// the regular expression given by the string form of the value of o.
+func (b BenchVarValue) match(o BenchVarValue) (bool, error) {
+	if b.Name != o.Name {
+		return false, errDifferentNames
+	}
+	expr, err := regexp.Compile(fmt.Sprint(o.Value))
+	if err != nil {
+		return false, err
+	}
+	return b.matchExpr(o.Name, expr)
+}
+
+// matchExpr reports whether the value of b, which must be a string and
+// have the provided name, matches the compiled regular expression.
+func (b BenchVarValue) matchExpr(name string, expr *regexp.Regexp) (bool, error) {
+	if b.Name != name {
+		return false, errDifferentNames
+	}
+	if reflect.ValueOf(b.Value).Kind() != reflect.String {
+		return false, errNonComparable
+	}
+	return expr.MatchString(reflect.ValueOf(b.Value).String()), nil
+}
+
 type varValComp struct {
 	varValue BenchVarValue
 	cmp      Comparison
+	expr     *regexp.Regexp // the compiled value of a match
+}
+
+// compare compares val against the value using the comparison. The
+// regular expression of a match is only compiled once, rather than for
+// every value compared.
+func (v varValComp) compare(val, value BenchVarValue) (bool, error) {
+	if v.expr == nil || (v.cmp != Match && v.cmp != NotMatch) {
+		return v.cmp.compare(val, value)
+	}
+	match, err := val.matchExpr(value.Name, v.expr)
+	if err != nil {
+		return false, compareErr{val1: val, val2: value, comparison: v.cmp, err: err}
+	}
+	return match == (v.cmp == Match), nil
 }
 
 func (v varValComp) String() string {
-	return fmt.Sprintf("%s%s%v", v.varValue.Name, v.cmp, v.varValue.Value)
+	val := v.varValue.formatValue()
+	if s, ok := v.varValue.Value.(string); ok && needsQuote(s, v.cmp) {
+		val = strconv.Quote(s)
+	}
+	return fmt.Sprintf("%s%s%s", v.varValue.Name, v.cmp, val)
+}
+
+// needsQuote reports whether a string value compared using cmp must be
+// quoted to be parsed as the same string by ParseFilter, since it would
+// otherwise be parsed as another type (e.g. "1") or split into multiple
+// comparisons. The expression of a match is always kept as a string.
+func needsQuote(s string, cmp Comparison) bool {
+	if strings.HasPrefix(s, `"`) || strings.TrimSpace(s) != s {
+		return true
+	}
+	if strings.Contains(s, filterAnd) || strings.Contains(s, filterOr) {
+		return true
+	}
+	if cmp != Match && cmp != NotMatch {
+		if v, ok := value(s).(string); !ok || v != s || strings.ContainsAny(s, "=!<>") {
+			return true
+		}
+	}
+	// parentheses are only kept as part of the value if balanced
+	depth := 0
+	for _, r := range s {
+		if r == '(' {
+			depth++
+		} else if r == ')' {
+			if depth--; depth < 0 {
+				return true
+			}
+		}
+	}
+	return depth != 0
 }
 
 func parseValueComparison(in string) (varValComp, error) {
+	if i := strings.Index(in, `"`); i >= 0 {
+		return parseQuotedComparison(in[:i], in[i:])
+	}
+
+	// the regular expression of a match may itself contain the other
+	// operators, so these are checked first
+	for _, cmp := range []Comparison{Match, NotMatch} {
+		split := strings.SplitN(in, string(cmp), 2)
+		if len(split) != 2 {
+			continue
+		}
+		expr, err := regexp.Compile(split[1])
+		if err != nil {
+			return varValComp{}, fmt.Errorf("%w: %s", errMalformedFilter, err)
+		}
+		return varValComp{
+			varValue: BenchVarValue{
+				Name:  split[0],
+				Value: split[1],
+			},
+			cmp:  cmp,
+			expr: expr,
+		}, nil
+	}
+
 	cmps := []Comparison{
 		Eq,
 		Ne,
@@ -137,13 +314,359 @@ func parseValueComparison(in string) (varValComp, error) {
 			continue
 		}
 		return varValComp{
+			varValue: newVarValue(split[0], split[1], 0),
+			cmp:      cmp,
+		}, nil
+	}
+
+	return varValComp{}, errMalformedFilter
+}
+
+// parseQuotedComparison parses a comparison against a quoted value,
+// which is always a string. lhs is the variable name followed by the
+// comparison operator.
+func parseQuotedComparison(lhs, quoted string) (varValComp, error) {
+	s, err := strconv.Unquote(quoted)
+	if err != nil {
+		return varValComp{}, fmt.Errorf("%w: invalid quoted value %s", errMalformedFilter, quoted)
+	}
+	cmps := []Comparison{
+		Match,
+		NotMatch,
+		Eq,
+		Ne,
+		Le,
+		Ge,
+		Lt,
+		Gt,
+	}
+	for _, cmp := range cmps {
+		if !strings.HasSuffix(lhs, string(cmp)) {
+			continue
+		}
+		v := varValComp{
 			varValue: BenchVarValue{
-				Name:  split[0],
-				Value: value(split[1]),
+				Name:  strings.TrimSuffix(lhs, string(cmp)),
+				Value: s,
 			},
 			cmp: cmp,
-		}, nil
+		}
+		if cmp == Match || cmp == NotMatch {
+			if v.expr, err = regexp.Compile(s); err != nil {
+				return varValComp{}, fmt.Errorf("%w: %s", errMalformedFilter, err)
+			}
+		}
+		return v, nil
 	}
-
 	return varValComp{}, errMalformedFilter
 }
+
+// Filter is a parsed filter expression, either a single comparison of
+// the form 'var_name==var_value' or a compound expression combining
+// comparisons with '&&' and '||', see ParseFilter.
+type Filter struct {
+	varValComp
+	node *filterNode // nil unless the filter is compound
+}
+
+// The logical operators joining the operands of a compound filter.
+const (
+	filterAnd = "&&"
+	filterOr  = "||"
+)
+
+// filterNode joins the operands of a compound filter.
+type filterNode struct {
+	op          string // filterAnd or filterOr
+	left, right Filter
+}
+
+// NewFilter constructs the filter comparing the named variable against
+// value using the provided comparison.
+func NewFilter(varName string, cmp Comparison, value interface{}) Filter {
+	v := varValComp{
+		varValue: BenchVarValue{Name: varName, Value: value},
+		cmp:      cmp,
+	}
+	if cmp == Match || cmp == NotMatch {
+		// an invalid expression is reported when filtering
+		v.expr, _ = regexp.Compile(fmt.Sprint(value))
+	}
+	return Filter{varValComp: v}
+}
+
+// ParseFilter parses a filter expression, as accepted by BenchResults.Filter.
+//
+// Bool variables can only be compared with bools using '==' and '!=',
+// with a value of 1 or 0 treated as true or false respectively, so
+// 'abs_val==1' is equivalent to 'abs_val==true'. Any other value
+// results in an error.
+//
+// String variables can be matched against a regular expression with
+// '=~' and '!~', for example 'y=~sin' or 'y!~(?i)cos'. The expression
+// is kept as a string rather than parsed as a value, and comparing it
+// against a variable which isn't a string results in an error.
+//
+// A value can be quoted as a Go string literal, in which case it is
+// always a string, for example 'id=="010"' or 'y=="a||b"'.
+//
+// Comparisons can be combined with '&&' and '||' and grouped with
+// parentheses, for example '(start_x>=0 || end_x<=0) && abs_val==true'.
+// As in Go, '&&' binds tighter than '||'.
+func ParseFilter(expr string) (Filter, error) {
+	p := &filterParser{in: expr}
+	f, err := p.parseOr()
+	if err == nil && p.skipSpace() < len(expr) {
+		err = fmt.Errorf("%w: unexpected %q at position %d", errMalformedFilter, expr[p.pos:], p.pos)
+	}
+	if err != nil {
+		return Filter{}, fmt.Errorf("error parsing %s: %w", expr, err)
+	}
+	return f, nil
+}
+
+// filterParser is a recursive descent parser of filter expressions.
+type filterParser struct {
+	in  string
+	pos int
+}
+
+// skipSpace advances past any whitespace, returning the new position.
+func (p *filterParser) skipSpace() int {
+	for p.pos < len(p.in) && (p.in[p.pos] == ' ' || p.in[p.pos] == '\t') {
+		p.pos++
+	}
+	return p.pos
+}
+
+// consume advances past tok if it is next, reporting whether it was.
+func (p *filterParser) consume(tok string) bool {
+	if strings.HasPrefix(p.in[p.skipSpace():], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+// skipQuoted advances to the closing quote of the quoted value starting
+// at the current position, or to the end of the input if unterminated.
+func (p *filterParser) skipQuoted() {
+	for p.pos++; p.pos < len(p.in) && p.in[p.pos] != '"'; p.pos++ {
+		if p.in[p.pos] == '\\' {
+			p.pos++
+		}
+	}
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return Filter{}, err
+	}
+	for p.consume(filterOr) {
+		right, err := p.parseAnd()
+		if err != nil {
+			return Filter{}, err
+		}
+		left = Filter{node: &filterNode{op: filterOr, left: left, right: right}}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return Filter{}, err
+	}
+	for p.consume(filterAnd) {
+		right, err := p.parseOperand()
+		if err != nil {
+			return Filter{}, err
+		}
+		left = Filter{node: &filterNode{op: filterAnd, left: left, right: right}}
+	}
+	return left, nil
+}
+
+// parseOperand parses either a parenthesized expression or a single
+// comparison. Parentheses within a comparison's value (e.g. 'y==sin(x)')
+// are kept as part of the value.
+func (p *filterParser) parseOperand() (Filter, error) {
+	if p.consume("(") {
+		f, err := p.parseOr()
+		if err != nil {
+			return Filter{}, err
+		}
+		if !p.consume(")") {
+			return Filter{}, fmt.Errorf("%w: missing ')' at position %d", errMalformedFilter, p.pos)
+		}
+		return f, nil
+	}
+
+	start, depth := p.pos, 0
+	for ; p.pos < len(p.in); p.pos++ {
+		if p.in[p.pos] == '"' {
+			p.skipQuoted()
+			continue
+		}
+		if depth == 0 && (strings.HasPrefix(p.in[p.pos:], filterAnd) || strings.HasPrefix(p.in[p.pos:], filterOr)) {
+			break
+		}
+		if p.in[p.pos] == '(' {
+			depth++
+		} else if p.in[p.pos] == ')' {
+			if depth == 0 {
+				break
+			}
+			depth--
+		}
+	}
+	varValCmp, err := parseValueComparison(strings.TrimSpace(p.in[start:p.pos]))
+	if err != nil {
+		return Filter{}, fmt.Errorf("%w at position %d", err, start)
+	}
+	return Filter{varValComp: varValCmp}, nil
+}
+
+// String returns the filter expression, which can be parsed
+// by ParseFilter to construct an equivalent filter. For example
+// NewFilter("delta", Gt, 0.01).String() returns "delta>0.01". String
+// values which would otherwise be parsed differently are quoted, so
+// NewFilter("y", Eq, "1").String() returns `y=="1"`.
+func (f Filter) String() string {
+	if f.node == nil {
+		return f.varValComp.String()
+	}
+	left, right := f.node.left.String(), f.node.right.String()
+	if f.node.op == filterAnd {
+		// '||' has lower precedence so must be parenthesized
+		if f.node.left.isOr() {
+			left = "(" + left + ")"
+		}
+		if f.node.right.isOr() {
+			right = "(" + right + ")"
+		}
+	}
+	return left + " " + f.node.op + " " + right
+}
+
+func (f Filter) isOr() bool {
+	return f.node != nil && f.node.op == filterOr
+}
+
+// matches reports whether the inputs of the result satisfy the filter.
+func (f Filter) matches(res BenchRes) (bool, error) {
+	if f.node != nil {
+		left, err := f.node.left.matches(res)
+		if err != nil {
+			return false, err
+		}
+		if (f.node.op == filterAnd && !left) || (f.node.op == filterOr && left) {
+			return left, nil
+		}
+		return f.node.right.matches(res)
+	}
+	if res.isMetricKey(f.varValue.Name) {
+		v, err := metricValue(res.Outputs, f.varValue.Name)
+		if err != nil {
+			if errors.Is(err, ErrNotMeasured) {
+				return false, nil
+			}
+			return false, err
+		}
+		value := reflect.ValueOf(f.varValue.Value)
+		if limit, err := getFloat(value, value.Kind()); err == nil {
+			return f.cmp.CompareFloat(v, limit)
+		}
+		return f.cmp.compare(BenchVarValue{Name: f.varValue.Name, Value: v}, f.varValue)
+	}
+	if f.varValue.Name == SubPathVar {
+		// compare the sub path as a string regardless of how the
+		// filter value was parsed
+		value := BenchVarValue{Name: SubPathVar, Value: fmt.Sprint(f.varValue.Value)}
+		return f.compare(BenchVarValue{Name: SubPathVar, Value: res.Inputs.SubPath()}, value)
+	}
+	for _, varVal := range res.Inputs.VarValues {
+		include, err := f.compare(varVal, f.coercedValue(varVal))
+		if err != nil {
+			if !errors.Is(err, errDifferentNames) {
+				return false, err
+			}
+			continue
+		}
+		if include {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// coercedValue returns the value the variable is compared against,
+// converted to the type of varVal where the filter's value is ambiguous.
+// Bools are only comparable with bools, so a value of 1 or 0 compared
+// against a bool variable is treated as true or false respectively.
+func (f Filter) coercedValue(varVal BenchVarValue) BenchVarValue {
+	if _, ok := varVal.Value.(bool); !ok {
+		return f.varValue
+	}
+	coerced := f.varValue
+	switch f.varValue.Value {
+	case 1:
+		coerced.Value = true
+	case 0:
+		coerced.Value = false
+	}
+	return coerced
+}
+
+// isMetricName reports whether name refers to an output metric rather
+// than an input variable, e.g. in a filter expression. This is the case
+// for "N" (the number of iterations), the standard metrics, and custom
+// metrics registered with RegisterMetric.
+func isMetricName(name string) bool {
+	if name == "N" || isOutputMetric(name) {
+		return true
+	}
+	_, ok := registeredMetric(name)
+	return ok
+}
+
+// isMetricKey reports whether name refers to an output metric of the
+// result rather than one of its input variables. An input variable takes
+// precedence over a metric of the same name, e.g. for
+// 'BenchmarkSort/N=1000' the name "N" refers to the variable rather than
+// the number of iterations.
+func (b BenchRes) isMetricKey(name string) bool {
+	if !isMetricName(name) {
+		return false
+	}
+	_, isVar := b.Inputs.VarValue(name)
+	return !isVar
+}
+
+// VarName returns the name of the variable being filtered on.
+// As with Comparison, Value, and ValueKind this is only meaningful for
+// a single comparison, and the zero value is returned for compound
+// filters.
+func (f Filter) VarName() string {
+	return f.varValue.Name
+}
+
+// Comparison returns the comparison operation of the filter.
+func (f Filter) Comparison() Comparison {
+	return f.cmp
+}
+
+// Value returns the value the variable is compared against.
+func (f Filter) Value() interface{} {
+	return f.varValue.Value
+}
+
+// ValueKind returns the kind the filter's value was parsed as. Values are
+// parsed as an int, float64, or bool when possible and as a string otherwise,
+// so this can be used to check how a value will be compared.
+// For example 'y==2' will compare y against the int 2, which will result
+// in an error if y is a string variable.
+func (f Filter) ValueKind() reflect.Kind {
+	return reflect.ValueOf(f.varValue.Value).Kind()
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -284,6 +286,45 @@ func TestCompareInvalidComparison(t *testing.T) {
 	}
 }
 
+func TestCompareFloat(t *testing.T) {
+	tests := []struct {
+		cmp      Comparison
+		a, b     float64
+		eps      float64
+		expected bool
+	}{
+		{cmp: Eq, a: 1.5, b: 1.5, expected: true},
+		{cmp: Eq, a: 0.30000000000000004, b: 0.3, expected: false},
+		{cmp: Eq, a: 0.30000000000000004, b: 0.3, eps: 1e-9, expected: true},
+		{cmp: Ne, a: 0.30000000000000004, b: 0.3, eps: 1e-9, expected: false},
+		{cmp: Lt, a: 1, b: 2, expected: true},
+		{cmp: Lt, a: 1, b: 1.05, eps: 0.1, expected: false},
+		{cmp: Gt, a: 2, b: 1, expected: true},
+		{cmp: Gt, a: 1.05, b: 1, eps: 0.1, expected: false},
+		{cmp: Le, a: 1.05, b: 1, eps: 0.1, expected: true},
+		{cmp: Le, a: 2, b: 1, expected: false},
+		{cmp: Ge, a: 1, b: 1.05, eps: 0.1, expected: true},
+		{cmp: Ge, a: 1, b: 2, expected: false},
+	}
+
+	for _, test := range tests {
+		actual, err := test.cmp.CompareFloatEps(test.a, test.b, test.eps)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if actual != test.expected {
+			t.Errorf("unexpected result of (%g)%s(%g) with eps=%g (expected=%t, actual=%t)", test.a, test.cmp, test.b, test.eps, test.expected, actual)
+		}
+	}
+
+	if _, err := Match.CompareFloat(1, 2); !errors.Is(err, errOperationNotDefined) {
+		t.Errorf("unexpected error\nexpected=%s\nactual=%s", errOperationNotDefined, err)
+	}
+	if _, err := Comparison("_").CompareFloat(1, 2); !errors.Is(err, errInvalidOperation) {
+		t.Errorf("unexpected error\nexpected=%s\nactual=%s", errInvalidOperation, err)
+	}
+}
+
 var parseValueComparisonTests = map[string]struct {
 	expectedVarValCmp varValComp
 	expectedString    string
@@ -331,6 +372,25 @@ var parseValueComparisonTests = map[string]struct {
 		},
 		expectedString: "var_1<=1",
 	},
+	"y=~^sin(<|>)": {
+		expectedVarValCmp: varValComp{
+			varValue: BenchVarValue{Name: "y", Value: "^sin(<|>)"},
+			cmp:      Match,
+			expr:     regexp.MustCompile("^sin(<|>)"),
+		},
+		expectedString: "y=~^sin(<|>)",
+	},
+	"y!~2": {
+		expectedVarValCmp: varValComp{
+			varValue: BenchVarValue{Name: "y", Value: "2"},
+			cmp:      NotMatch,
+			expr:     regexp.MustCompile("2"),
+		},
+		expectedString: "y!~2",
+	},
+	"y=~sin(": {
+		expectErr: true,
+	},
 	"var1,2": {
 		expectErr: true,
 	},
@@ -357,3 +417,216 @@ func TestParseValueComparison(t *testing.T) {
 		})
 	}
 }
+
+var parseFilterTests = map[string]struct {
+	expectedVarName    string
+	expectedComparison Comparison
+	expectedValue      interface{}
+	expectedKind       reflect.Kind
+	expectedErr        error
+}{
+	"y==2": {
+		expectedVarName:    "y",
+		expectedComparison: Eq,
+		expectedValue:      2,
+		expectedKind:       reflect.Int,
+	},
+	"delta>0.01": {
+		expectedVarName:    "delta",
+		expectedComparison: Gt,
+		expectedValue:      0.01,
+		expectedKind:       reflect.Float64,
+	},
+	"abs_val!=true": {
+		expectedVarName:    "abs_val",
+		expectedComparison: Ne,
+		expectedValue:      true,
+		expectedKind:       reflect.Bool,
+	},
+	"y==sin(x)": {
+		expectedVarName:    "y",
+		expectedComparison: Eq,
+		expectedValue:      "sin(x)",
+		expectedKind:       reflect.String,
+	},
+	"y,2": {
+		expectedErr: errMalformedFilter,
+	},
+}
+
+func TestParseFilter(t *testing.T) {
+	for testInput, testCase := range parseFilterTests {
+		t.Run(testInput, func(t *testing.T) {
+			f, err := ParseFilter(testInput)
+			if err != nil {
+				if !errors.Is(err, testCase.expectedErr) {
+					t.Errorf("unexpected error\nexpected=%s\nactual=%s", testCase.expectedErr, err)
+				}
+				return
+			}
+			if testCase.expectedErr != nil {
+				t.Fatalf("unexpectedly no error")
+			}
+
+			if f.VarName() != testCase.expectedVarName {
+				t.Errorf("unexpected var name (expected=%s, actual=%s)", testCase.expectedVarName, f.VarName())
+			}
+			if f.Comparison() != testCase.expectedComparison {
+				t.Errorf("unexpected comparison (expected=%s, actual=%s)", testCase.expectedComparison, f.Comparison())
+			}
+			if !reflect.DeepEqual(f.Value(), testCase.expectedValue) {
+				t.Errorf("unexpected value (expected=%v, actual=%v)", testCase.expectedValue, f.Value())
+			}
+			if f.ValueKind() != testCase.expectedKind {
+				t.Errorf("unexpected value kind (expected=%s, actual=%s)", testCase.expectedKind, f.ValueKind())
+			}
+		})
+	}
+}
+
+var filterStringTests = map[string]struct {
+	filter         Filter
+	expectedString string
+}{
+	"float": {
+		filter:         NewFilter("delta", Gt, 0.01),
+		expectedString: "delta>0.01",
+	},
+	"integral_float": {
+		filter:         NewFilter("delta", Le, 1.0),
+		expectedString: "delta<=1.0",
+	},
+	"large_float": {
+		filter:         NewFilter("delta", Lt, 1e21),
+		expectedString: "delta<1e+21",
+	},
+	"int": {
+		filter:         NewFilter("start_x", Ne, -2),
+		expectedString: "start_x!=-2",
+	},
+	"string": {
+		filter:         NewFilter("y", Eq, "sin(x)"),
+		expectedString: "y==sin(x)",
+	},
+	"bool": {
+		filter:         NewFilter("abs_val", Eq, true),
+		expectedString: "abs_val==true",
+	},
+	"numeric_string": {
+		filter:         NewFilter("y", Eq, "1"),
+		expectedString: `y=="1"`,
+	},
+	"string_with_operator": {
+		filter:         NewFilter("y", Eq, "a||b"),
+		expectedString: `y=="a||b"`,
+	},
+	"string_with_unbalanced_paren": {
+		filter:         NewFilter("y", Ne, "sin(x"),
+		expectedString: `y!="sin(x"`,
+	},
+	"string_with_quote": {
+		filter:         NewFilter("y", Eq, `"a"`),
+		expectedString: `y=="\"a\""`,
+	},
+	"quoted_match": {
+		filter:         NewFilter("y", Match, "^(a|b)$|c&&d"),
+		expectedString: `y=~"^(a|b)$|c&&d"`,
+	},
+}
+
+func TestFilterString(t *testing.T) {
+	for testName, testCase := range filterStringTests {
+		t.Run(testName, func(t *testing.T) {
+			s := testCase.filter.String()
+			if s != testCase.expectedString {
+				t.Errorf("unexpected string (expected=%s, actual=%s)", testCase.expectedString, s)
+			}
+
+			parsed, err := ParseFilter(s)
+			if err != nil {
+				t.Fatalf("unexpected error parsing %s: %s", s, err)
+			}
+			if !reflect.DeepEqual(parsed, testCase.filter) {
+				t.Errorf("unexpected filter after round trip\nexpected:\n%v\nactual:\n%v", testCase.filter, parsed)
+			}
+		})
+	}
+}
+
+var compoundFilterStringTests = map[string]struct {
+	expectedString string
+}{
+	"delta<1&&y==sin(x)": {
+		expectedString: "delta<1 && y==sin(x)",
+	},
+	"y==sin(x) || delta<1 && start_x==-1": {
+		expectedString: "y==sin(x) || delta<1 && start_x==-1",
+	},
+	"(y==sin(x) || delta<1) && start_x==-1": {
+		expectedString: "(y==sin(x) || delta<1) && start_x==-1",
+	},
+	"((delta<1))": {
+		expectedString: "delta<1",
+	},
+	"size<=1024KB": {
+		expectedString: "size<=1024KB",
+	},
+	"timeout==60s": {
+		expectedString: "timeout==60s",
+	},
+	"mask!=0xff": {
+		expectedString: "mask!=0xff",
+	},
+	`id=="010" && y=="a||b"`: {
+		expectedString: `id=="010" && y=="a||b"`,
+	},
+}
+
+func TestCompoundFilterString(t *testing.T) {
+	for testInput, testCase := range compoundFilterStringTests {
+		t.Run(testInput, func(t *testing.T) {
+			f, err := ParseFilter(testInput)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			s := f.String()
+			if s != testCase.expectedString {
+				t.Errorf("unexpected string (expected=%s, actual=%s)", testCase.expectedString, s)
+			}
+
+			parsed, err := ParseFilter(s)
+			if err != nil {
+				t.Fatalf("unexpected error parsing %s: %s", s, err)
+			}
+			if !reflect.DeepEqual(parsed, f) {
+				t.Errorf("unexpected filter after round trip\nexpected:\n%v\nactual:\n%v", f, parsed)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrorPosition(t *testing.T) {
+	_, err := ParseFilter("delta<1 && y,2")
+	if !errors.Is(err, errMalformedFilter) {
+		t.Fatalf("unexpected error\nexpected=%s\nactual=%s", errMalformedFilter, err)
+	}
+	if !strings.Contains(err.Error(), "position 11") {
+		t.Errorf("error does not contain position: %s", err)
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	tests := map[string]error{
+		"y,2":          ErrMalformedFilter,
+		"abs_val<true": ErrOperationNotDefined,
+		"y>2":          ErrNonComparable,
+	}
+	for filterExpr, expected := range tests {
+		t.Run(filterExpr, func(t *testing.T) {
+			_, err := sampleBench.Results.Filter(filterExpr)
+			if !errors.Is(err, expected) {
+				t.Errorf("unexpected error\nexpected=%s\nactual=%s", expected, err)
+			}
+		})
+	}
+}
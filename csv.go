@@ -0,0 +1,91 @@
+package benchparse
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// WriteCSV writes the benchmarks to w as CSV in wide format, with a
+// header row followed by one row per result. The columns are those of
+// the Table of the benchmarks (see NewTable), so include each input
+// variable across all of the benchmarks sorted by name and each
+// measured metric, with absent variables and unmeasured metrics left
+// empty.
+func WriteCSV(w io.Writer, benches []Benchmark) error {
+	var (
+		cw    = csv.NewWriter(w)
+		table = NewTable(benches)
+	)
+	if err := cw.Write(table.Header); err != nil {
+		return err
+	}
+	return cw.WriteAll(table.Rows)
+}
+
+// WriteTidyCSV writes the benchmarks to w as CSV in long (or "tidy")
+// format, with one row per measured metric of each result. This is
+// the format expected by plotting tools such as ggplot, and is more
+// convenient than a wide table (see NewTable) for faceted plots.
+//
+// The columns are the benchmark name, the sub-benchmark names, each
+// input variable sorted by name, GOMAXPROCS, the number of iterations,
+// the metric, and its value. The standard metrics of each result are
+// written in the testing.B output order, followed by any custom
+// metrics sorted by unit. Absent variables are left empty.
+func WriteTidyCSV(w io.Writer, benches []Benchmark) error {
+	var (
+		cw       = csv.NewWriter(w)
+		varNames = allVarNames(benches)
+	)
+
+	header := []string{"benchmark", "subs"}
+	header = append(header, varNames...)
+	header = append(header, "procs", "iterations", "metric", "value")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, bench := range benches {
+		for _, res := range bench.Results {
+			prefix := make([]string, 0, len(header))
+			prefix = append(prefix, bench.Name, res.Inputs.SubPath())
+			for _, name := range varNames {
+				prefix = append(prefix, varValueCell(res.Inputs, name))
+			}
+			prefix = append(prefix, strconv.Itoa(res.Inputs.MaxProcs), strconv.Itoa(res.Outputs.GetIterations()))
+
+			for _, m := range measuredMetrics(res.Outputs) {
+				row := append(prefix, m.metric, strconv.FormatFloat(m.value, 'f', -1, 64))
+				if err := cw.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// measurement is the value of a single metric.
+type measurement struct {
+	metric string
+	value  float64
+}
+
+// measuredMetrics returns the value of each standard metric which was
+// measured, in the testing.B output order, followed by any custom
+// metrics.
+func measuredMetrics(b BenchOutputs) []measurement {
+	measurements := []measurement{}
+	for _, metric := range outputMetrics {
+		if v, err := metricValue(b, metric); err == nil {
+			measurements = append(measurements, measurement{metric: metric, value: v})
+		}
+	}
+	for _, m := range sortedCustomMetrics(b) {
+		measurements = append(measurements, measurement{metric: m.unit, value: m.value})
+	}
+	return measurements
+}
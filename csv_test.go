@@ -0,0 +1,136 @@
+package benchparse
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+var writeCSVTests = map[string]struct {
+	benches  []Benchmark
+	expected string
+}{
+	"sample": {
+		benches: []Benchmark{
+			{Name: sampleBench.Name, Results: sampleBench.Results[1:3]},
+			{Name: "BenchmarkFoo", Results: BenchResults{
+				{
+					Inputs:  BenchInputs{VarValues: []BenchVarValue{{Name: "size", Value: 8, position: 1}}, MaxProcs: 1},
+					Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, NsPerOp: 12.3, MBPerS: 5.5, Measured: parse.NsPerOp | parse.MBPerS}},
+				},
+			}},
+		},
+		expected: `benchmark,subs,abs_val,delta,end_x,size,start_x,y,procs,iterations,ns/op,MB/s,B/op,allocs/op
+BenchmarkMath,areaUnder,false,1,2,,-1,2x+3,4,88335925,13.3,,0,0
+BenchmarkMath,max,,0.001,1,,-2,2x+3,4,56282,20361,,0,0
+BenchmarkFoo,,,,,8,,,1,100,12.3,5.5,,
+`,
+	},
+	"custom_metrics": {
+		benches: []Benchmark{{Name: "BenchmarkFoo", Results: BenchResults{
+			{
+				Inputs: BenchInputs{MaxProcs: 8},
+				Outputs: parsedBenchOutputs{
+					Benchmark: parse.Benchmark{N: 100, NsPerOp: 12.3, Measured: parse.NsPerOp},
+					extra:     map[string]float64{"items/op": 4.5, "hits/op": 0.9},
+				},
+			},
+			{
+				Inputs:  BenchInputs{MaxProcs: 8},
+				Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 200, NsPerOp: 10, Measured: parse.NsPerOp}},
+			},
+		}}},
+		expected: `benchmark,subs,procs,iterations,ns/op,hits/op,items/op
+BenchmarkFoo,,8,100,12.3,0.9,4.5
+BenchmarkFoo,,8,200,10,,
+`,
+	},
+	"no_benchmarks": {
+		benches:  []Benchmark{},
+		expected: "benchmark,subs,procs,iterations\n",
+	},
+}
+
+func TestWriteCSV(t *testing.T) {
+	for testName, testCase := range writeCSVTests {
+		t.Run(testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, testCase.benches); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if buf.String() != testCase.expected {
+				t.Errorf("unexpected output\nexpected:\n%s\nactual:\n%s", testCase.expected, buf.String())
+			}
+		})
+	}
+}
+
+var writeTidyCSVTests = map[string]struct {
+	benches  []Benchmark
+	expected string
+}{
+	"sample": {
+		benches: []Benchmark{{Name: sampleBench.Name, Results: sampleBench.Results[1:3]}},
+		expected: `benchmark,subs,abs_val,delta,end_x,start_x,y,procs,iterations,metric,value
+BenchmarkMath,areaUnder,false,1,2,-1,2x+3,4,88335925,ns/op,13.3
+BenchmarkMath,areaUnder,false,1,2,-1,2x+3,4,88335925,B/op,0
+BenchmarkMath,areaUnder,false,1,2,-1,2x+3,4,88335925,allocs/op,0
+BenchmarkMath,max,,0.001,1,-2,2x+3,4,56282,ns/op,20361
+BenchmarkMath,max,,0.001,1,-2,2x+3,4,56282,B/op,0
+BenchmarkMath,max,,0.001,1,-2,2x+3,4,56282,allocs/op,0
+`,
+	},
+	"custom_metrics": {
+		benches: []Benchmark{{Name: "BenchmarkFoo", Results: BenchResults{
+			{
+				Inputs: BenchInputs{MaxProcs: 8},
+				Outputs: parsedBenchOutputs{
+					Benchmark: parse.Benchmark{N: 100, NsPerOp: 12.3, MBPerS: 5.5, Measured: parse.NsPerOp | parse.MBPerS},
+					extra:     map[string]float64{"items/op": 4.5, "hits/op": 0.9},
+				},
+			},
+		}}},
+		expected: `benchmark,subs,procs,iterations,metric,value
+BenchmarkFoo,,8,100,ns/op,12.3
+BenchmarkFoo,,8,100,MB/s,5.5
+BenchmarkFoo,,8,100,hits/op,0.9
+BenchmarkFoo,,8,100,items/op,4.5
+`,
+	},
+	"no_benchmarks": {
+		benches:  []Benchmark{},
+		expected: "benchmark,subs,procs,iterations,metric,value\n",
+	},
+}
+
+func TestWriteTidyCSV(t *testing.T) {
+	for testName, testCase := range writeTidyCSVTests {
+		t.Run(testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteTidyCSV(&buf, testCase.benches); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if buf.String() != testCase.expected {
+				t.Errorf("unexpected output\nexpected:\n%s\nactual:\n%s", testCase.expected, buf.String())
+			}
+		})
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, errors.New("test error") }
+
+func TestWriteTidyCSVWriteErr(t *testing.T) {
+	if err := WriteTidyCSV(errWriter{}, []Benchmark{sampleBench}); err == nil {
+		t.Errorf("unexpectedly no error")
+	}
+}
+
+func TestWriteCSVWriteErr(t *testing.T) {
+	if err := WriteCSV(errWriter{}, []Benchmark{sampleBench}); err == nil {
+		t.Errorf("unexpectedly no error")
+	}
+}
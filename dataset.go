@@ -0,0 +1,186 @@
+package benchparse
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Dataset converts the benchmark's results into feature and target
+// vectors, e.g. for training a model to predict performance from the
+// inputs. Each row of features corresponds to the target at the same
+// index, and each column is named by featureNames. Results where the
+// target metric was not measured are skipped.
+//
+// Input variables are encoded in order of their name as follows:
+//
+//   - variables with only numeric values are a single feature with the
+//     same name as the variable, with NaN used for results without the
+//     variable.
+//   - all other variables (e.g. strings and bools) are one-hot encoded,
+//     with a feature named 'var_name=var_value' for each distinct value
+//     in order of the value's string representation. A feature is 1 if
+//     the result has that value and 0 otherwise.
+//
+// If any result has sub-benchmarks the sub path (see BenchInputs.SubPath)
+// is one-hot encoded as a variable named SubPathVar, after the input
+// variables.
+func (b Benchmark) Dataset(targetMetric string) (features [][]float64, targets []float64, featureNames []string, err error) {
+	results, targets := BenchResults{}, []float64{}
+	for _, res := range b.Results {
+		v, err := metricValue(res.Outputs, targetMetric)
+		if err != nil {
+			if errors.Is(err, ErrNotMeasured) {
+				continue
+			}
+			return nil, nil, nil, err
+		}
+		results = append(results, res)
+		targets = append(targets, v)
+	}
+
+	var (
+		encoders = []featureEncoder{}
+		byName   = map[string][]interface{}{}
+		hasSubs  bool
+	)
+	for _, res := range results {
+		for _, varVal := range res.Inputs.VarValues {
+			byName[varVal.Name] = append(byName[varVal.Name], varVal.Value)
+		}
+		hasSubs = hasSubs || len(res.Inputs.Subs) != 0
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		encoders = append(encoders, newFeatureEncoder(name, byName[name]))
+	}
+	if hasSubs {
+		subPaths := make([]interface{}, len(results))
+		for i, res := range results {
+			subPaths[i] = res.Inputs.SubPath()
+		}
+		encoders = append(encoders, newOneHotEncoder(SubPathVar, subPaths, subPath))
+	}
+
+	featureNames = []string{}
+	for _, enc := range encoders {
+		featureNames = append(featureNames, enc.names()...)
+	}
+	features = make([][]float64, len(results))
+	for i, res := range results {
+		row := make([]float64, 0, len(featureNames))
+		for _, enc := range encoders {
+			row = append(row, enc.encode(res.Inputs)...)
+		}
+		features[i] = row
+	}
+	return features, targets, featureNames, nil
+}
+
+// featureEncoder encodes a single input variable as one or more
+// features.
+type featureEncoder interface {
+	names() []string
+	encode(inputs BenchInputs) []float64
+}
+
+// newFeatureEncoder returns the encoder for the variable with the
+// provided values.
+func newFeatureEncoder(name string, values []interface{}) featureEncoder {
+	for _, v := range values {
+		if !isNumeric(reflect.ValueOf(v).Kind()) {
+			return newOneHotEncoder(name, values, varValue(name))
+		}
+	}
+	return numericEncoder{name: name}
+}
+
+type numericEncoder struct {
+	name string
+}
+
+func (n numericEncoder) names() []string {
+	return []string{n.name}
+}
+
+func (n numericEncoder) encode(inputs BenchInputs) []float64 {
+	for _, varVal := range inputs.VarValues {
+		if varVal.Name != n.name {
+			continue
+		}
+		v := reflect.ValueOf(varVal.Value)
+		if f, err := getFloat(v, v.Kind()); err == nil {
+			return []float64{f}
+		}
+	}
+	return []float64{math.NaN()}
+}
+
+type oneHotEncoder struct {
+	name   string
+	values []string // the distinct values, sorted
+
+	// value returns the value of the variable, or false if the
+	// inputs don't have the variable.
+	value func(inputs BenchInputs) (string, bool)
+}
+
+func newOneHotEncoder(name string, values []interface{}, value func(inputs BenchInputs) (string, bool)) oneHotEncoder {
+	var (
+		seen     = map[string]bool{}
+		distinct = []string{}
+	)
+	for _, v := range values {
+		s := fmt.Sprint(v)
+		if !seen[s] {
+			seen[s] = true
+			distinct = append(distinct, s)
+		}
+	}
+	sort.Strings(distinct)
+	return oneHotEncoder{name: name, values: distinct, value: value}
+}
+
+func (o oneHotEncoder) names() []string {
+	names := make([]string, len(o.values))
+	for i, v := range o.values {
+		names[i] = fmt.Sprintf("%s=%s", o.name, v)
+	}
+	return names
+}
+
+func (o oneHotEncoder) encode(inputs BenchInputs) []float64 {
+	encoded := make([]float64, len(o.values))
+	value, ok := o.value(inputs)
+	if !ok {
+		return encoded
+	}
+	for i, v := range o.values {
+		if v == value {
+			encoded[i] = 1
+		}
+	}
+	return encoded
+}
+
+// varValue returns a function returning the string representation of
+// the named variable's value.
+func varValue(name string) func(inputs BenchInputs) (string, bool) {
+	return func(inputs BenchInputs) (string, bool) {
+		v, ok := inputs.Value(name)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprint(v), true
+	}
+}
+
+func subPath(inputs BenchInputs) (string, bool) {
+	return inputs.SubPath(), true
+}
@@ -0,0 +1,97 @@
+package benchparse
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+var datasetTests = map[string]struct {
+	bench                Benchmark
+	targetMetric         string
+	expectedFeatures     [][]float64
+	expectedTargets      []float64
+	expectedFeatureNames []string
+	expectedErr          error
+}{
+	"sample": {
+		bench:        sampleBench,
+		targetMetric: "ns/op",
+		expectedFeatures: [][]float64{
+			{0, 1, 0.001, 1, -2, 0, 1, 1, 0},
+			{1, 0, 1, 2, -1, 1, 0, 1, 0},
+			{0, 0, 0.001, 1, -2, 1, 0, 0, 1},
+			{0, 0, 1, 2, -1, 0, 1, 0, 1},
+		},
+		expectedTargets: []float64{55357, 13.3, 20361, 62.7},
+		expectedFeatureNames: []string{
+			"abs_val=false", "abs_val=true",
+			"delta", "end_x", "start_x",
+			"y=2x+3", "y=sin(x)",
+			"sub_path=areaUnder", "sub_path=max",
+		},
+	},
+	"missing_numeric_var": {
+		bench: Benchmark{Name: "BenchmarkFoo", Results: BenchResults{
+			{
+				Inputs:  BenchInputs{VarValues: []BenchVarValue{{Name: "size", Value: 10, position: 1}}},
+				Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, NsPerOp: 5, Measured: parse.NsPerOp}},
+			},
+			{
+				Inputs:  BenchInputs{VarValues: []BenchVarValue{}},
+				Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, NsPerOp: 6, Measured: parse.NsPerOp}},
+			},
+			{
+				Inputs:  BenchInputs{VarValues: []BenchVarValue{{Name: "size", Value: 20, position: 1}}},
+				Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, Measured: 0}},
+			},
+		}},
+		targetMetric:         "ns/op",
+		expectedFeatures:     [][]float64{{10}, {math.NaN()}},
+		expectedTargets:      []float64{5, 6},
+		expectedFeatureNames: []string{"size"},
+	},
+	"not_measured": {
+		bench:                sampleBench,
+		targetMetric:         "MB/s",
+		expectedFeatures:     [][]float64{},
+		expectedTargets:      []float64{},
+		expectedFeatureNames: []string{},
+	},
+	"unknown_metric": {
+		bench:        sampleBench,
+		targetMetric: "foo/op",
+		expectedErr:  errUnknownMetric,
+	},
+}
+
+func TestDataset(t *testing.T) {
+	for testName, testCase := range datasetTests {
+		t.Run(testName, func(t *testing.T) {
+			features, targets, featureNames, err := testCase.bench.Dataset(testCase.targetMetric)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if testCase.expectedErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(featureNames, testCase.expectedFeatureNames) {
+				t.Errorf("unexpected feature names\nexpected:\n%v\nactual:\n%v", testCase.expectedFeatureNames, featureNames)
+			}
+			if !reflect.DeepEqual(targets, testCase.expectedTargets) {
+				t.Errorf("unexpected targets\nexpected:\n%v\nactual:\n%v", testCase.expectedTargets, targets)
+			}
+			if len(features) != len(testCase.expectedFeatures) {
+				t.Fatalf("unexpected number of rows (expected=%d, actual=%d)", len(testCase.expectedFeatures), len(features))
+			}
+			for i, row := range features {
+				if !floatsEqual(row, testCase.expectedFeatures[i]) {
+					t.Errorf("unexpected features for row %d\nexpected:\n%v\nactual:\n%v", i, testCase.expectedFeatures[i], row)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package benchparse
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Summary summarizes how the results of a benchmark changed between
+// two runs.
+type Summary struct {
+	Improved  int
+	Regressed int
+	Unchanged int
+	FromZero  int // cases where the metric changed from zero, e.g. new allocations
+	Changed   int // cases which changed for a metric of unknown direction
+
+	ImprovedInputs  []BenchInputs
+	RegressedInputs []BenchInputs
+	UnchangedInputs []BenchInputs
+	FromZeroInputs  []BenchInputs
+	ChangedInputs   []BenchInputs
+}
+
+// CompareSummary compares the results of two runs of a benchmark by the
+// named metric (e.g. "ns/op"), counting the cases which improved,
+// regressed, or were unchanged. Results are matched by their inputs,
+// with the mean used for inputs with multiple results. Cases only
+// present in one run or where the metric is missing from either run
+// are not included.
+//
+// A case is unchanged if the absolute percent change of the metric is
+// at most threshold (e.g. 5 for 5%). Whether a change is an improvement
+// depends on the metric: lower values of "ns/op", "B/op", and "allocs/op"
+// are better, while higher values of "MB/s" are better, see
+// MetricDirection. For a metric of UnknownDirection, such as "N" or a
+// custom metric registered without a direction, a case which changed
+// by more than threshold is counted as Changed rather than as improved
+// or regressed.
+//
+// A case where the metric is zero in both runs (e.g. a zero-alloc code
+// path) is unchanged, while a case where the metric changed from zero to
+// non-zero has no meaningful percent change so is counted as FromZero
+// rather than as improved or regressed.
+func CompareSummary(old, new Benchmark, metric string, threshold float64) (Summary, error) {
+	deltas, err := ResultDeltas(old, new, metric)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summary{
+		ImprovedInputs:  []BenchInputs{},
+		RegressedInputs: []BenchInputs{},
+		UnchangedInputs: []BenchInputs{},
+		FromZeroInputs:  []BenchInputs{},
+		ChangedInputs:   []BenchInputs{},
+	}
+	dir := MetricDirection(metric)
+	for _, d := range deltas {
+		change, ok := percentChange(d.Old, d.New)
+		switch {
+		case !ok:
+			summary.FromZero++
+			summary.FromZeroInputs = append(summary.FromZeroInputs, d.Inputs)
+		case math.Abs(change) <= threshold:
+			summary.Unchanged++
+			summary.UnchangedInputs = append(summary.UnchangedInputs, d.Inputs)
+		case dir == UnknownDirection:
+			summary.Changed++
+			summary.ChangedInputs = append(summary.ChangedInputs, d.Inputs)
+		case (change < 0) != (dir == HigherIsBetter):
+			summary.Improved++
+			summary.ImprovedInputs = append(summary.ImprovedInputs, d.Inputs)
+		default:
+			summary.Regressed++
+			summary.RegressedInputs = append(summary.RegressedInputs, d.Inputs)
+		}
+	}
+	return summary, nil
+}
+
+// ResultDelta is the change in a metric between two runs of a single
+// case of a benchmark.
+type ResultDelta struct {
+	Inputs BenchInputs
+	Metric string
+	Old    float64
+	New    float64
+}
+
+// Speedup returns the change formatted as a speedup factor, e.g.
+// "1.5x faster". Unlike SpeedupString this accounts for the metric, so
+// an increase in a metric where higher values are better (e.g. "MB/s")
+// is reported as faster.
+func (d ResultDelta) Speedup() string {
+	if MetricDirection(d.Metric) == HigherIsBetter {
+		return SpeedupString(d.New, d.Old)
+	}
+	return SpeedupString(d.Old, d.New)
+}
+
+// ResultDeltas returns the change in the named metric for each case
+// present in both runs of a benchmark, in the order the cases appear
+// in new. Results are matched by their inputs, with the mean used for
+// inputs with multiple results. Cases where the metric is missing from
+// either run are not included. These are the cases compared by Compare
+// which are neither Added nor Removed.
+func ResultDeltas(old, new Benchmark, metric string) ([]ResultDelta, error) {
+	compared, err := Compare(old, new, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := []ResultDelta{}
+	for _, d := range compared {
+		if d.Added || d.Removed {
+			continue
+		}
+		deltas = append(deltas, d.resultDelta())
+	}
+	return deltas, nil
+}
+
+// Delta is the change in a metric between two runs of a single case of
+// a benchmark, see Compare.
+type Delta struct {
+	Inputs        BenchInputs
+	Metric        string
+	OldValue      float64 // NaN if Added
+	NewValue      float64 // NaN if Removed
+	PercentChange float64 // NaN if Added, Removed, or OldValue is zero while NewValue is not
+
+	Added   bool // the case is only present in new
+	Removed bool // the case is only present in old
+}
+
+// Speedup returns the change formatted as a speedup factor, as
+// ResultDelta.Speedup does, or an empty string if the case is only
+// present in one run.
+func (d Delta) Speedup() string {
+	if d.Added || d.Removed {
+		return ""
+	}
+	return d.resultDelta().Speedup()
+}
+
+func (d Delta) resultDelta() ResultDelta {
+	return ResultDelta{Inputs: d.Inputs, Metric: d.Metric, Old: d.OldValue, New: d.NewValue}
+}
+
+// Compare compares two runs of a benchmark by the named metric,
+// similar to benchstat. Results are matched by their inputs, with the
+// mean used for inputs with multiple results.
+//
+// A Delta is returned for each case present in either run: first for
+// those in new, in the order they appear, followed by those only in
+// old. Cases only present in one run are flagged as Added or Removed,
+// while cases present in both runs where the metric is missing from
+// either run are not included.
+func Compare(old, new Benchmark, metric string) ([]Delta, error) {
+	oldVals, err := meanByInputs(old.Results, metric)
+	if err != nil {
+		return nil, err
+	}
+	newVals, err := meanByInputs(new.Results, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		oldKeys  = inputKeys(old.Results)
+		newKeys  = inputKeys(new.Results)
+		oldByKey = make(map[string]float64, len(oldVals))
+		nan      = math.NaN()
+		deltas   = make([]Delta, 0, len(newVals))
+	)
+	for _, o := range oldVals {
+		oldByKey[o.inputs.key()] = o.mean
+	}
+	for _, n := range newVals {
+		k := n.inputs.key()
+		if o, ok := oldByKey[k]; ok {
+			change, defined := percentChange(o, n.mean)
+			if !defined {
+				change = nan
+			}
+			deltas = append(deltas, Delta{Inputs: n.inputs, Metric: metric, OldValue: o, NewValue: n.mean, PercentChange: change})
+		} else if !oldKeys[k] {
+			deltas = append(deltas, Delta{Inputs: n.inputs, Metric: metric, OldValue: nan, NewValue: n.mean, PercentChange: nan, Added: true})
+		}
+	}
+	for _, o := range oldVals {
+		if !newKeys[o.inputs.key()] {
+			deltas = append(deltas, Delta{Inputs: o.inputs, Metric: metric, OldValue: o.mean, NewValue: nan, PercentChange: nan, Removed: true})
+		}
+	}
+	return deltas, nil
+}
+
+// inputKeys returns the keys of the inputs of the results.
+func inputKeys(results BenchResults) map[string]bool {
+	keys := make(map[string]bool, len(results))
+	for _, res := range results {
+		keys[res.Inputs.key()] = true
+	}
+	return keys
+}
+
+// SpeedupString formats the change from old to new as a speedup factor,
+// treating the values as costs such as a duration, so "1.5x faster" if
+// new is two thirds of old and "2.0x slower" if new is twice old. If
+// the values are equal "no change" is returned.
+func SpeedupString(old, new float64) string {
+	switch {
+	case old == new:
+		return "no change"
+	case new < old:
+		return fmt.Sprintf("%sx faster", formatFactor(old/new))
+	default:
+		return fmt.Sprintf("%sx slower", formatFactor(new/old))
+	}
+}
+
+// formatFactor formats a speedup factor, which is infinite if one of
+// the values was zero.
+func formatFactor(f float64) string {
+	if math.IsInf(f, 0) {
+		return "∞"
+	}
+	return strconv.FormatFloat(f, 'f', 1, 64)
+}
+
+// percentChange returns the percent change from old to new. If old
+// is zero the change is 0 if new is also zero and undefined otherwise,
+// in which case false is returned.
+func percentChange(old, new float64) (float64, bool) {
+	if old == 0 {
+		return 0, new == 0
+	}
+	return (new - old) / math.Abs(old) * 100, true
+}
+
+type inputMean struct {
+	inputs BenchInputs
+	mean   float64
+}
+
+// meanByInputs returns the mean of the metric for each distinct input,
+// in the order the inputs first appear. Results where the metric was not
+// measured are skipped.
+func meanByInputs(results BenchResults, metric string) ([]inputMean, error) {
+	byInputs, err := results.valuesByInputs(metric)
+	if err != nil {
+		return nil, err
+	}
+	means := make([]inputMean, len(byInputs))
+	for i, in := range byInputs {
+		means[i] = inputMean{inputs: in.inputs, mean: mean(in.values)}
+	}
+	return means, nil
+}
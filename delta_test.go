@@ -0,0 +1,306 @@
+package benchparse
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+var compareSummaryTests = map[string]struct {
+	old             Benchmark
+	new             Benchmark
+	metric          string
+	threshold       float64
+	expectedSummary Summary
+	expectedErr     error
+}{
+	"ns_per_op": {
+		old:       withMetric(sampleBench, "ns/op", 100, 100, 100, 100),
+		new:       withMetric(sampleBench, "ns/op", 80, 103, 120, 100),
+		metric:    "ns/op",
+		threshold: 5,
+		expectedSummary: Summary{
+			Improved:        1,
+			Regressed:       1,
+			Unchanged:       2,
+			ImprovedInputs:  []BenchInputs{sampleBench.Results[0].Inputs},
+			RegressedInputs: []BenchInputs{sampleBench.Results[2].Inputs},
+			UnchangedInputs: []BenchInputs{sampleBench.Results[1].Inputs, sampleBench.Results[3].Inputs},
+			FromZeroInputs:  []BenchInputs{},
+			ChangedInputs:   []BenchInputs{},
+		},
+	},
+	"unmatched_cases_ignored": {
+		old:       withMetric(sampleBench, "ns/op", 100, 100),
+		new:       withMetric(sampleBench, "ns/op", 100, 200, 300),
+		metric:    "ns/op",
+		threshold: 5,
+		expectedSummary: Summary{
+			Regressed:       1,
+			Unchanged:       1,
+			ImprovedInputs:  []BenchInputs{},
+			RegressedInputs: []BenchInputs{sampleBench.Results[1].Inputs},
+			UnchangedInputs: []BenchInputs{sampleBench.Results[0].Inputs},
+			FromZeroInputs:  []BenchInputs{},
+			ChangedInputs:   []BenchInputs{},
+		},
+	},
+	"unmeasured_metric": {
+		old:       sampleBench,
+		new:       sampleBench,
+		metric:    "MB/s",
+		threshold: 5,
+		expectedSummary: Summary{
+			ImprovedInputs:  []BenchInputs{},
+			RegressedInputs: []BenchInputs{},
+			UnchangedInputs: []BenchInputs{},
+			FromZeroInputs:  []BenchInputs{},
+			ChangedInputs:   []BenchInputs{},
+		},
+	},
+	"zero_allocs": {
+		old:       sampleBench,
+		new:       withMetric(sampleBench, "allocs/op", 0, 2, 0, 1),
+		metric:    "allocs/op",
+		threshold: 5,
+		expectedSummary: Summary{
+			Unchanged:       2,
+			FromZero:        2,
+			ImprovedInputs:  []BenchInputs{},
+			RegressedInputs: []BenchInputs{},
+			UnchangedInputs: []BenchInputs{sampleBench.Results[0].Inputs, sampleBench.Results[2].Inputs},
+			FromZeroInputs:  []BenchInputs{sampleBench.Results[1].Inputs, sampleBench.Results[3].Inputs},
+			ChangedInputs:   []BenchInputs{},
+		},
+	},
+	"unknown_direction": {
+		old:       sampleBench,
+		new:       withMetric(sampleBench, "ns/op", 100, 100, 100, 100),
+		metric:    "N",
+		threshold: 5,
+		expectedSummary: Summary{
+			Changed:         4,
+			ImprovedInputs:  []BenchInputs{},
+			RegressedInputs: []BenchInputs{},
+			UnchangedInputs: []BenchInputs{},
+			FromZeroInputs:  []BenchInputs{},
+			ChangedInputs: []BenchInputs{
+				sampleBench.Results[0].Inputs,
+				sampleBench.Results[1].Inputs,
+				sampleBench.Results[2].Inputs,
+				sampleBench.Results[3].Inputs,
+			},
+		},
+	},
+	"unknown_metric": {
+		old:         sampleBench,
+		new:         sampleBench,
+		metric:      "foo/op",
+		expectedErr: errUnknownMetric,
+	},
+}
+
+func TestCompareSummary(t *testing.T) {
+	for testName, testCase := range compareSummaryTests {
+		t.Run(testName, func(t *testing.T) {
+			summary, err := CompareSummary(testCase.old, testCase.new, testCase.metric, testCase.threshold)
+			if err != nil {
+				if !errors.Is(err, testCase.expectedErr) {
+					t.Errorf("unexpected error\nexpected=%s\nactual=%s", testCase.expectedErr, err)
+				}
+				return
+			}
+			if testCase.expectedErr != nil {
+				t.Fatalf("unexpectedly no error")
+			}
+
+			if !reflect.DeepEqual(summary, testCase.expectedSummary) {
+				t.Errorf("unexpected summary\nexpected:\n%#v\nactual:\n%#v", testCase.expectedSummary, summary)
+			}
+		})
+	}
+}
+
+var percentChangeTests = map[string]struct {
+	old, new       float64
+	expectedChange float64
+	expectedOK     bool
+}{
+	"increase":        {old: 100, new: 150, expectedChange: 50, expectedOK: true},
+	"decrease":        {old: 100, new: 75, expectedChange: -25, expectedOK: true},
+	"zero_to_zero":    {old: 0, new: 0, expectedChange: 0, expectedOK: true},
+	"zero_to_nonzero": {old: 0, new: 3, expectedChange: 0, expectedOK: false},
+	"nonzero_to_zero": {old: 3, new: 0, expectedChange: -100, expectedOK: true},
+}
+
+func TestPercentChange(t *testing.T) {
+	for testName, testCase := range percentChangeTests {
+		t.Run(testName, func(t *testing.T) {
+			change, ok := percentChange(testCase.old, testCase.new)
+			if change != testCase.expectedChange || ok != testCase.expectedOK {
+				t.Errorf("unexpected percent change (expected=%v,%t, actual=%v,%t)", testCase.expectedChange, testCase.expectedOK, change, ok)
+			}
+		})
+	}
+}
+
+var speedupStringTests = map[string]struct {
+	old      float64
+	new      float64
+	expected string
+}{
+	"faster":       {old: 150, new: 100, expected: "1.5x faster"},
+	"slower":       {old: 100, new: 200, expected: "2.0x slower"},
+	"no_change":    {old: 100, new: 100, expected: "no change"},
+	"to_zero":      {old: 100, new: 0, expected: "∞x faster"},
+	"from_zero":    {old: 0, new: 2, expected: "∞x slower"},
+	"small_change": {old: 100, new: 101, expected: "1.0x slower"},
+}
+
+func TestSpeedupString(t *testing.T) {
+	for testName, testCase := range speedupStringTests {
+		t.Run(testName, func(t *testing.T) {
+			s := SpeedupString(testCase.old, testCase.new)
+			if s != testCase.expected {
+				t.Errorf("unexpected string (expected=%q, actual=%q)", testCase.expected, s)
+			}
+		})
+	}
+}
+
+var resultDeltasTests = map[string]struct {
+	old              Benchmark
+	new              Benchmark
+	metric           string
+	expectedDeltas   []ResultDelta
+	expectedSpeedups []string
+}{
+	"ns_per_op": {
+		old:    withMetric(sampleBench, "ns/op", 150, 100, 100),
+		new:    withMetric(sampleBench, "ns/op", 100, 200),
+		metric: "ns/op",
+		expectedDeltas: []ResultDelta{
+			{Inputs: sampleBench.Results[0].Inputs, Metric: "ns/op", Old: 150, New: 100},
+			{Inputs: sampleBench.Results[1].Inputs, Metric: "ns/op", Old: 100, New: 200},
+		},
+		expectedSpeedups: []string{"1.5x faster", "2.0x slower"},
+	},
+	"mb_per_s": {
+		old: Benchmark{Name: sampleBench.Name, Results: BenchResults{
+			{Inputs: sampleBench.Results[0].Inputs, Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, MBPerS: 100, Measured: parse.MBPerS}}},
+			{Inputs: sampleBench.Results[1].Inputs, Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, MBPerS: 100, Measured: parse.MBPerS}}},
+		}},
+		new: Benchmark{Name: sampleBench.Name, Results: BenchResults{
+			{Inputs: sampleBench.Results[0].Inputs, Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, MBPerS: 150, Measured: parse.MBPerS}}},
+			{Inputs: sampleBench.Results[1].Inputs, Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, MBPerS: 50, Measured: parse.MBPerS}}},
+		}},
+		metric: "MB/s",
+		expectedDeltas: []ResultDelta{
+			{Inputs: sampleBench.Results[0].Inputs, Metric: "MB/s", Old: 100, New: 150},
+			{Inputs: sampleBench.Results[1].Inputs, Metric: "MB/s", Old: 100, New: 50},
+		},
+		expectedSpeedups: []string{"1.5x faster", "2.0x slower"},
+	},
+}
+
+func TestResultDeltas(t *testing.T) {
+	for testName, testCase := range resultDeltasTests {
+		t.Run(testName, func(t *testing.T) {
+			deltas, err := ResultDeltas(testCase.old, testCase.new, testCase.metric)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(deltas, testCase.expectedDeltas) {
+				t.Fatalf("unexpected deltas\nexpected:\n%v\nactual:\n%v", testCase.expectedDeltas, deltas)
+			}
+			for i, d := range deltas {
+				if s := d.Speedup(); s != testCase.expectedSpeedups[i] {
+					t.Errorf("unexpected speedup for delta %d (expected=%q, actual=%q)", i, testCase.expectedSpeedups[i], s)
+				}
+			}
+		})
+	}
+}
+
+var compareBenchmarksTests = map[string]struct {
+	old              Benchmark
+	new              Benchmark
+	metric           string
+	expectedDeltas   []Delta
+	expectedSpeedups []string
+	expectedErr      error
+}{
+	"added_and_removed": {
+		old:    withMetric(sampleBench, "ns/op", 100, 200, 0),
+		new:    Benchmark{Name: sampleBench.Name, Results: withMetric(sampleBench, "ns/op", 50, 300, 10, 40).Results[1:]},
+		metric: "ns/op",
+		expectedDeltas: []Delta{
+			{Inputs: sampleBench.Results[1].Inputs, OldValue: 200, NewValue: 300, PercentChange: 50},
+			{Inputs: sampleBench.Results[2].Inputs, OldValue: 0, NewValue: 10, PercentChange: math.NaN()},
+			{Inputs: sampleBench.Results[3].Inputs, OldValue: math.NaN(), NewValue: 40, PercentChange: math.NaN(), Added: true},
+			{Inputs: sampleBench.Results[0].Inputs, OldValue: 100, NewValue: math.NaN(), PercentChange: math.NaN(), Removed: true},
+		},
+		expectedSpeedups: []string{"1.5x slower", "∞x slower", "", ""},
+	},
+	"repeated_results": {
+		old: withMetric(sampleBench, "ns/op", 100),
+		new: Benchmark{Name: sampleBench.Name, Results: append(
+			withMetric(sampleBench, "ns/op", 40).Results,
+			withMetric(sampleBench, "ns/op", 60).Results...,
+		)},
+		metric: "ns/op",
+		expectedDeltas: []Delta{
+			{Inputs: sampleBench.Results[0].Inputs, OldValue: 100, NewValue: 50, PercentChange: -50},
+		},
+		expectedSpeedups: []string{"2.0x faster"},
+	},
+	"not_measured": {
+		old:    withMetric(sampleBench, "allocs/op", 1, 2),
+		new:    withMetric(sampleBench, "ns/op", 10, 20, 30),
+		metric: "ns/op",
+		expectedDeltas: []Delta{
+			{Inputs: sampleBench.Results[2].Inputs, OldValue: math.NaN(), NewValue: 30, PercentChange: math.NaN(), Added: true},
+		},
+		expectedSpeedups: []string{""},
+	},
+	"unknown_metric": {
+		old:         withMetric(sampleBench, "ns/op", 100),
+		new:         withMetric(sampleBench, "ns/op", 100),
+		metric:      "foo/op",
+		expectedErr: errUnknownMetric,
+	},
+}
+
+func TestCompareBenchmarks(t *testing.T) {
+	for testName, testCase := range compareBenchmarksTests {
+		t.Run(testName, func(t *testing.T) {
+			deltas, err := Compare(testCase.old, testCase.new, testCase.metric)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if len(deltas) != len(testCase.expectedDeltas) {
+				t.Fatalf("unexpected deltas\nexpected:\n%+v\nactual:\n%+v", testCase.expectedDeltas, deltas)
+			}
+			for i, d := range deltas {
+				expected := testCase.expectedDeltas[i]
+				values := []float64{d.OldValue, d.NewValue, d.PercentChange}
+				expectedValues := []float64{expected.OldValue, expected.NewValue, expected.PercentChange}
+				if !floatsEqual(values, expectedValues) {
+					t.Errorf("unexpected values of delta %d (expected=%v, actual=%v)", i, expectedValues, values)
+				}
+				if d.Inputs.String() != expected.Inputs.String() || d.Added != expected.Added || d.Removed != expected.Removed {
+					t.Errorf("unexpected delta %d\nexpected:\n%+v\nactual:\n%+v", i, expected, d)
+				}
+				if d.Metric != testCase.metric {
+					t.Errorf("unexpected metric of delta %d (expected=%s, actual=%s)", i, testCase.metric, d.Metric)
+				}
+				if s := d.Speedup(); s != testCase.expectedSpeedups[i] {
+					t.Errorf("unexpected speedup for delta %d (expected=%q, actual=%q)", i, testCase.expectedSpeedups[i], s)
+				}
+			}
+		})
+	}
+}
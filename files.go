@@ -0,0 +1,132 @@
+package benchparse
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ParseBenchmarksFiles extracts a list of benchmarks from the testing.B
+// output in each of the files at paths, see ParseBenchmarksAuto. The
+// files are read and parsed concurrently, with at most GOMAXPROCS files
+// parsed at once.
+//
+// Benchmarks with the same name (and package, see Benchmark.Pkg) in
+// different files are merged, with the results ordered by the order of
+// paths. The sample indices of the merged results are numbered across
+// all of the files, see BenchRes.SampleIndex.
+//
+// If any of the files can't be parsed, a *FilesError holding the error
+// of each such file is returned.
+func ParseBenchmarksFiles(paths []string, opts ...ParseOption) ([]Benchmark, error) {
+	parsed := make([][]Benchmark, len(paths))
+	errs := make([]*FileError, len(paths))
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				benches, err := parseBenchmarksFile(paths[i], opts...)
+				if err != nil {
+					errs[i] = &FileError{Path: paths[i], Err: err}
+					continue
+				}
+				parsed[i] = benches
+			}
+		}()
+	}
+	for i := range paths {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	filesErr := &FilesError{}
+	for _, err := range errs {
+		if err != nil {
+			filesErr.Errs = append(filesErr.Errs, err)
+		}
+	}
+	if len(filesErr.Errs) != 0 {
+		return nil, filesErr
+	}
+	return mergeBenchmarks(parsed), nil
+}
+
+func parseBenchmarksFile(path string, opts ...ParseOption) ([]Benchmark, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseBenchmarksAuto(f, opts...)
+}
+
+// mergeBenchmarks combines benchmarks with the same name and package
+// from each list, in the order they first appear.
+func mergeBenchmarks(lists [][]Benchmark) []Benchmark {
+	var (
+		merged  = []Benchmark{}
+		indices = map[string]int{}
+		samples = sampleCounter{}
+	)
+	for _, benches := range lists {
+		for _, bench := range benches {
+			key := bench.Name
+			if bench.Pkg != "" {
+				key = bench.Pkg + "." + bench.Name
+			}
+			i, ok := indices[key]
+			if !ok {
+				i = len(merged)
+				indices[key] = i
+				merged = append(merged, Benchmark{Name: bench.Name, Results: BenchResults{}, Pkg: bench.Pkg})
+			}
+			for _, res := range bench.Results {
+				res.sampleIndex = samples.next(key, res.Inputs)
+				merged[i].Results = append(merged[i].Results, res)
+			}
+		}
+	}
+	return merged
+}
+
+// FileError is the error encountered parsing a single file.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (f *FileError) Error() string {
+	return fmt.Sprintf("%s: %s", f.Path, f.Err)
+}
+
+// Unwrap returns the underlying error, allowing it to be checked with
+// errors.Is and errors.As.
+func (f *FileError) Unwrap() error {
+	return f.Err
+}
+
+// FilesError is returned by ParseBenchmarksFiles if any of the files
+// can't be parsed, with the error of each such file in the order the
+// files were provided.
+type FilesError struct {
+	Errs []*FileError
+}
+
+func (f *FilesError) Error() string {
+	s := make([]string, len(f.Errs))
+	for i, err := range f.Errs {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
@@ -0,0 +1,92 @@
+package benchparse
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBenchmarksFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "benchparse")
+	if err != nil {
+		t.Fatalf("unexpected error creating dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	contents := []string{
+		"BenchmarkFoo/var=1-4 100 10 ns/op\nBenchmarkBar-4 100 20 ns/op\n",
+		"BenchmarkFoo/var=1-4 100 30 ns/op\n",
+		`{"Action":"output","Package":"example.com/foo","Output":"BenchmarkFoo/var=1-4 100 40 ns/op\n"}`,
+	}
+	paths := make([]string, len(contents))
+	for i, content := range contents {
+		paths[i] = filepath.Join(dir, string(rune('a'+i))+".txt")
+		if err := ioutil.WriteFile(paths[i], []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error writing file: %s", err)
+		}
+	}
+
+	benches, err := ParseBenchmarksFiles(paths)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	type summary struct {
+		name, pkg string
+		nsPerOp   []float64
+		samples   []int
+	}
+	expected := []summary{
+		{name: "BenchmarkFoo", nsPerOp: []float64{10, 30}, samples: []int{0, 1}},
+		{name: "BenchmarkBar", nsPerOp: []float64{20}, samples: []int{0}},
+		{name: "BenchmarkFoo", pkg: "example.com/foo", nsPerOp: []float64{40}, samples: []int{0}},
+	}
+	if len(benches) != len(expected) {
+		t.Fatalf("unexpected number of benchmarks (expected=%d, actual=%d)", len(expected), len(benches))
+	}
+	for i, bench := range benches {
+		actual := summary{name: bench.Name, pkg: bench.Pkg}
+		for _, res := range bench.Results {
+			v, err := res.Outputs.GetNsPerOp()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			actual.nsPerOp = append(actual.nsPerOp, v)
+			actual.samples = append(actual.samples, res.SampleIndex())
+		}
+		if !reflect.DeepEqual(actual, expected[i]) {
+			t.Errorf("unexpected benchmark %d\nexpected:\n%+v\nactual:\n%+v", i, expected[i], actual)
+		}
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		missing := []string{filepath.Join(dir, "missing1.txt"), paths[0], filepath.Join(dir, "missing2.txt")}
+		_, err := ParseBenchmarksFiles(missing)
+		if err == nil {
+			t.Fatalf("unexpectedly no error")
+		}
+		var filesErr *FilesError
+		if !errors.As(err, &filesErr) {
+			t.Fatalf("unexpected error type %T: %s", err, err)
+		}
+		if len(filesErr.Errs) != 2 {
+			t.Fatalf("unexpected number of errors (expected=2, actual=%d): %s", len(filesErr.Errs), err)
+		}
+		for i, path := range []string{missing[0], missing[2]} {
+			fileErr := filesErr.Errs[i]
+			if fileErr.Path != path {
+				t.Errorf("unexpected path (expected=%s, actual=%s)", path, fileErr.Path)
+			}
+			if !errors.Is(fileErr, os.ErrNotExist) {
+				t.Errorf("unexpected error: %s", fileErr)
+			}
+			if !strings.Contains(err.Error(), path) {
+				t.Errorf("error does not contain %s: %s", path, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package benchparse
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// HTMLOptions configure the report written by WriteHTML.
+type HTMLOptions struct {
+	Title string // the page title, defaults to "Benchmark Results"
+
+	// ChartMetric is the metric (e.g. "ns/op") to chart for each
+	// benchmark. If empty no charts are included.
+	ChartMetric string
+}
+
+// WriteHTML writes a self-contained HTML report of the benchmarks to w.
+// The report contains the Table of the benchmarks, which can be sorted
+// by clicking on a column header, and optionally an SVG bar chart of
+// a single metric for each benchmark.
+func WriteHTML(w io.Writer, benches []Benchmark, opts HTMLOptions) error {
+	title := opts.Title
+	if title == "" {
+		title = "Benchmark Results"
+	}
+
+	var charts []htmlChart
+	if opts.ChartMetric != "" {
+		if !isMetricName(opts.ChartMetric) {
+			return fmt.Errorf("%w: %s", errUnknownMetric, opts.ChartMetric)
+		}
+		charts = make([]htmlChart, len(benches))
+		for i, bench := range benches {
+			charts[i] = newHTMLChart(bench, opts.ChartMetric)
+		}
+	}
+
+	return htmlTemplate.Execute(w, struct {
+		Title  string
+		Table  Table
+		Charts []htmlChart
+	}{
+		Title:  title,
+		Table:  NewTable(benches),
+		Charts: charts,
+	})
+}
+
+const (
+	chartBarHeight  = 20
+	chartBarWidth   = 400
+	chartLabelWidth = 400
+	chartValueGap   = 5 // between the end of a bar and its value
+)
+
+type htmlChart struct {
+	Title  string
+	Width  int
+	Height int
+	Bars   []htmlChartBar
+}
+
+type htmlChartBar struct {
+	Label  string
+	Value  float64
+	X      int // the start of the bar, after the label
+	Y      int
+	Width  int
+	ValueX int // the start of the value, after the bar
+}
+
+func newHTMLChart(bench Benchmark, metric string) htmlChart {
+	var (
+		bars = []htmlChartBar{}
+		max  float64
+	)
+	for _, res := range bench.Results {
+		v, err := metricValue(res.Outputs, metric)
+		if err != nil {
+			continue
+		}
+		if v > max {
+			max = v
+		}
+		bars = append(bars, htmlChartBar{
+			Label: strings.TrimPrefix(res.Inputs.String(), "/"),
+			Value: v,
+			Y:     len(bars) * chartBarHeight,
+		})
+	}
+	for i := range bars {
+		if max > 0 {
+			bars[i].Width = int(bars[i].Value / max * chartBarWidth)
+		}
+		bars[i].X = chartLabelWidth
+		bars[i].ValueX = chartLabelWidth + bars[i].Width + chartValueGap
+	}
+
+	return htmlChart{
+		Title:  bench.Name + " (" + metric + ")",
+		Width:  chartLabelWidth + chartBarWidth + 100,
+		Height: len(bars) * chartBarHeight,
+		Bars:   bars,
+	}
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #eee; cursor: pointer; }
+tr:nth-child(even) td { background: #f8f8f8; }
+svg text { font-size: 12px; }
+svg rect { fill: #4a7ebb; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table class="results">
+<thead><tr>{{range .Table.Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Table.Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{- range .Charts}}
+<h2>{{.Title}}</h2>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}">
+{{- range .Bars}}
+<text x="0" y="{{.Y}}" dy="14">{{.Label}}</text>
+<rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="16"></rect>
+<text x="{{.ValueX}}" y="{{.Y}}" dy="14">{{.Value}}</text>
+{{- end}}
+</svg>
+{{- end}}
+<script>
+document.querySelectorAll("table.results th").forEach(function(th, col) {
+	th.addEventListener("click", function() {
+		var tbody = th.closest("table").tBodies[0];
+		var asc = th.dataset.order !== "asc";
+		th.dataset.order = asc ? "asc" : "desc";
+		var rows = Array.prototype.slice.call(tbody.rows);
+		rows.sort(function(a, b) {
+			var x = a.cells[col].textContent, y = b.cells[col].textContent;
+			if (x === "" || y === "") {
+				return (x === "") - (y === "");
+			}
+			var nx = Number(x), ny = Number(y), c;
+			if (!isNaN(nx) && !isNaN(ny)) {
+				c = nx - ny;
+			} else {
+				c = x < y ? -1 : (x > y ? 1 : 0);
+			}
+			return asc ? c : -c;
+		});
+		rows.forEach(function(r) { tbody.appendChild(r); });
+	});
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,71 @@
+package benchparse
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteHTML(&buf, []Benchmark{sampleBench}, HTMLOptions{Title: "Math <results>", ChartMetric: "ns/op"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	out := buf.String()
+	expectedSubstrings := []string{
+		"<title>Math &lt;results&gt;</title>",
+		"<th>ns/op</th>",
+		"<td>55357</td>",
+		"<h2>BenchmarkMath (ns/op)</h2>",
+		"areaUnder/y=sin(x)/delta=0.001/start_x=-2/end_x=1/abs_val=true-4",
+		`<rect x="400" y="0" width="400" height="16">`,
+		`<text x="805" y="0" dy="14">55357</text>`,
+	}
+	for _, expected := range expectedSubstrings {
+		if !strings.Contains(out, expected) {
+			t.Errorf("output missing %q:\n%s", expected, out)
+		}
+	}
+}
+
+func TestWriteHTMLCustomMetrics(t *testing.T) {
+	benches, err := ParseBenchmarks(strings.NewReader("BenchmarkFoo-4 100 10 ns/op 0.5 hits/op\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, benches, HTMLOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	out := buf.String()
+	for _, expected := range []string{"<th>hits/op</th>", "<td>0.5</td>"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("output missing %q:\n%s", expected, out)
+		}
+	}
+}
+
+func TestWriteHTMLNoCharts(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, []Benchmark{sampleBench}, HTMLOptions{}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "<title>Benchmark Results</title>") {
+		t.Errorf("output missing default title:\n%s", out)
+	}
+	if strings.Contains(out, "<svg") {
+		t.Errorf("unexpected chart in output:\n%s", out)
+	}
+}
+
+func TestWriteHTMLUnknownMetric(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteHTML(&buf, []Benchmark{sampleBench}, HTMLOptions{ChartMetric: "foo/op"})
+	if !errors.Is(err, errUnknownMetric) {
+		t.Errorf("unexpected error\nexpected=%s\nactual=%s", errUnknownMetric, err)
+	}
+}
@@ -0,0 +1,333 @@
+package benchparse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// benchmarkJSON is the JSON representation of a Benchmark. Along with
+// those of the types it contains, this allows parsed benchmarks to be
+// persisted and reloaded without re-parsing the testing.B output.
+type benchmarkJSON struct {
+	Name    string       `json:"name"`
+	Results BenchResults `json:"results"`
+	Pkg     string       `json:"pkg,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b Benchmark) MarshalJSON() ([]byte, error) {
+	return json.Marshal(benchmarkJSON{Name: b.Name, Results: b.Results, Pkg: b.Pkg})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Benchmark) UnmarshalJSON(data []byte) error {
+	var j benchmarkJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*b = Benchmark{Name: j.Name, Results: j.Results, Pkg: j.Pkg}
+	return nil
+}
+
+// benchResJSON is the JSON representation of a BenchRes.
+type benchResJSON struct {
+	Inputs      BenchInputs  `json:"inputs"`
+	Outputs     *outputsJSON `json:"outputs"`
+	Timestamp   *time.Time   `json:"timestamp,omitempty"`
+	SampleIndex int          `json:"sample_index,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. The outputs are encoded using
+// the BenchOutputs getters, so any implementation can be marshaled,
+// however they are always unmarshaled as the outputs of a parsed
+// result.
+func (b BenchRes) MarshalJSON() ([]byte, error) {
+	j := benchResJSON{Inputs: b.Inputs, SampleIndex: b.sampleIndex}
+	if b.Outputs != nil {
+		j.Outputs = newOutputsJSON(b.Outputs)
+	}
+	if !b.timestamp.IsZero() {
+		j.Timestamp = &b.timestamp
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *BenchRes) UnmarshalJSON(data []byte) error {
+	var j benchResJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	res := BenchRes{Inputs: j.Inputs, sampleIndex: j.SampleIndex}
+	if j.Outputs != nil {
+		res.Outputs = j.Outputs.outputs()
+	}
+	if j.Timestamp != nil {
+		res.timestamp = *j.Timestamp
+	}
+	*b = res
+	return nil
+}
+
+// outputsJSON is the JSON representation of a BenchOutputs, where
+// unmeasured metrics are omitted.
+type outputsJSON struct {
+	Name              string             `json:"name,omitempty"`
+	Ord               int                `json:"ord,omitempty"`
+	N                 int                `json:"n"`
+	NsPerOp           *float64           `json:"ns_per_op,omitempty"`
+	AllocedBytesPerOp *uint64            `json:"alloced_bytes_per_op,omitempty"`
+	AllocsPerOp       *uint64            `json:"allocs_per_op,omitempty"`
+	MBPerS            *float64           `json:"mb_per_s,omitempty"`
+	Custom            map[string]float64 `json:"custom,omitempty"`
+}
+
+func newOutputsJSON(b BenchOutputs) *outputsJSON {
+	j := &outputsJSON{N: b.GetIterations()}
+	if raw, ok := b.(RawOutputs); ok {
+		j.Name, j.Ord = raw.Unwrap().Name, raw.Unwrap().Ord
+	}
+	if v, err := b.GetNsPerOp(); err == nil {
+		j.NsPerOp = &v
+	}
+	if v, err := b.GetAllocedBytesPerOp(); err == nil {
+		j.AllocedBytesPerOp = &v
+	}
+	if v, err := b.GetAllocsPerOp(); err == nil {
+		j.AllocsPerOp = &v
+	}
+	if v, err := b.GetMBPerS(); err == nil {
+		j.MBPerS = &v
+	}
+	if custom := reportedMetrics(b); len(custom) != 0 {
+		j.Custom = custom
+	}
+	return j
+}
+
+func (j *outputsJSON) outputs() parsedBenchOutputs {
+	outputs := parsedBenchOutputs{
+		Benchmark: parse.Benchmark{Name: j.Name, Ord: j.Ord, N: j.N},
+		extra:     j.Custom,
+	}
+	if j.NsPerOp != nil {
+		outputs.NsPerOp = *j.NsPerOp
+		outputs.Measured |= parse.NsPerOp
+	}
+	if j.AllocedBytesPerOp != nil {
+		outputs.AllocedBytesPerOp = *j.AllocedBytesPerOp
+		outputs.Measured |= parse.AllocedBytesPerOp
+	}
+	if j.AllocsPerOp != nil {
+		outputs.AllocsPerOp = *j.AllocsPerOp
+		outputs.Measured |= parse.AllocsPerOp
+	}
+	if j.MBPerS != nil {
+		outputs.MBPerS = *j.MBPerS
+		outputs.Measured |= parse.MBPerS
+	}
+	return outputs
+}
+
+// benchInputsJSON is the JSON representation of a BenchInputs.
+type benchInputsJSON struct {
+	Subs      []BenchSub      `json:"subs"`
+	VarValues []BenchVarValue `json:"var_values"`
+	MaxProcs  int             `json:"max_procs"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b BenchInputs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(benchInputsJSON{Subs: b.Subs, VarValues: b.VarValues, MaxProcs: b.MaxProcs})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *BenchInputs) UnmarshalJSON(data []byte) error {
+	var j benchInputsJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*b = BenchInputs{Subs: j.Subs, VarValues: j.VarValues, MaxProcs: j.MaxProcs}
+	return nil
+}
+
+// benchSubJSON is the JSON representation of a BenchSub.
+type benchSubJSON struct {
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b BenchSub) MarshalJSON() ([]byte, error) {
+	return json.Marshal(benchSubJSON{Name: b.Name, Position: b.position})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *BenchSub) UnmarshalJSON(data []byte) error {
+	var j benchSubJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*b = BenchSub{Name: j.Name, position: j.Position}
+	return nil
+}
+
+// benchVarValueJSON is the JSON representation of a BenchVarValue. Since
+// JSON doesn't distinguish between integers and floats, the kind of the
+// value is included so the value is unmarshaled with the same type.
+// Non-finite floats are encoded as strings, time.Duration values have
+// the type "duration" and are encoded as strings such as "500ms", and
+// ByteSize values have the type "byte_size" and are encoded as the
+// number of bytes.
+type benchVarValueJSON struct {
+	Name     string          `json:"name"`
+	Type     string          `json:"type"`
+	Value    json.RawMessage `json:"value"`
+	Position int             `json:"position"`
+	Text     string          `json:"text,omitempty"`
+}
+
+var errUnsupportedValue = errors.New("unsupported variable value type")
+
+// MarshalJSON implements json.Marshaler. Values are limited to
+// booleans, strings, and numbers, as produced when parsing, and are
+// unmarshaled as the corresponding builtin type.
+func (b BenchVarValue) MarshalJSON() ([]byte, error) {
+	v := reflect.ValueOf(b.Value)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("%w: %s has no value", errUnsupportedValue, b.Name)
+	}
+
+	var (
+		encoded interface{}
+		typ     = v.Kind().String()
+	)
+	switch k := v.Kind(); {
+	case v.Type() == durationType:
+		encoded, typ = b.Value.(time.Duration).String(), "duration"
+	case v.Type() == byteSizeType:
+		encoded, typ = json.Number(strconv.FormatInt(v.Int(), 10)), "byte_size"
+	case k == reflect.Bool:
+		encoded = v.Bool()
+	case k == reflect.String:
+		encoded = v.String()
+	case k == reflect.Float32 || k == reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			encoded = strconv.FormatFloat(f, 'g', -1, 64)
+		} else {
+			encoded = json.Number(strconv.FormatFloat(f, 'g', -1, v.Type().Bits()))
+		}
+	case isUint(k):
+		encoded = json.Number(strconv.FormatUint(v.Uint(), 10))
+	case isNumeric(k):
+		encoded = json.Number(strconv.FormatInt(v.Int(), 10))
+	default:
+		return nil, fmt.Errorf("%w: %s (%T)", errUnsupportedValue, b, b.Value)
+	}
+
+	value, err := json.Marshal(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(benchVarValueJSON{
+		Name:     b.Name,
+		Type:     typ,
+		Value:    value,
+		Position: b.position,
+		Text:     b.text,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *BenchVarValue) UnmarshalJSON(data []byte) error {
+	var j benchVarValueJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	value, err := decodeVarValue(j.Type, j.Value)
+	if err != nil {
+		return fmt.Errorf("error decoding value of %s: %w", j.Name, err)
+	}
+	*b = BenchVarValue{Name: j.Name, Value: value, position: j.Position, text: j.Text}
+	return nil
+}
+
+// decodeVarValue decodes the JSON encoded variable value of the named
+// kind, see BenchVarValue.MarshalJSON.
+func decodeVarValue(kind string, data json.RawMessage) (interface{}, error) {
+	switch kind {
+	case "duration":
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		return time.ParseDuration(s)
+	case "byte_size":
+		n, err := strconv.ParseInt(string(data), 10, 64)
+		return ByteSize(n), err
+	case "bool":
+		var v bool
+		err := json.Unmarshal(data, &v)
+		return v, err
+	case "string":
+		var v string
+		err := json.Unmarshal(data, &v)
+		return v, err
+	case "float32", "float64":
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			// finite floats are encoded as numbers
+			s = string(data)
+		}
+		bits := 64
+		if kind == "float32" {
+			bits = 32
+		}
+		f, err := strconv.ParseFloat(s, bits)
+		if bits == 32 {
+			return float32(f), err
+		}
+		return f, err
+	case "int", "int8", "int16", "int32", "int64":
+		i, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(i).Convert(kindTypes[kind]).Interface(), nil
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		u, err := strconv.ParseUint(string(data), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(u).Convert(kindTypes[kind]).Interface(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedValue, kind)
+	}
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+)
+
+// kindTypes are the builtin integer types, keyed by kind.
+var kindTypes = map[string]reflect.Type{
+	"int":    reflect.TypeOf(int(0)),
+	"int8":   reflect.TypeOf(int8(0)),
+	"int16":  reflect.TypeOf(int16(0)),
+	"int32":  reflect.TypeOf(int32(0)),
+	"int64":  reflect.TypeOf(int64(0)),
+	"uint":   reflect.TypeOf(uint(0)),
+	"uint8":  reflect.TypeOf(uint8(0)),
+	"uint16": reflect.TypeOf(uint16(0)),
+	"uint32": reflect.TypeOf(uint32(0)),
+	"uint64": reflect.TypeOf(uint64(0)),
+}
@@ -0,0 +1,99 @@
+package benchparse
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBenchmarkJSONRoundTrip(t *testing.T) {
+	parsed, err := ParseBenchmarksFromJSON(strings.NewReader(`{"Time":"2020-06-20T15:04:05.123456789-04:00","Action":"output","Output":"BenchmarkFoo/size=1/mode=fast/ratio=0.5/enabled=true-8 \t100\t12.3 ns/op\t5.50 MB/s\t16 B/op\t1 allocs/op\t0.9 hits/op\n"}
+{"Time":"2020-06-20T15:04:06Z","Action":"output","Output":"BenchmarkFoo/size=2/mode=slow/ratio=1.0/enabled=false-8 \t200\t24.6 ns/op\n"}
+`), WithTimestamps())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, bench := range append(parsed, sampleBench, Benchmark{Name: "BenchmarkEmpty"}) {
+		t.Run(bench.Name, func(t *testing.T) {
+			data, err := json.Marshal(bench)
+			if err != nil {
+				t.Fatalf("unexpected error marshaling: %s", err)
+			}
+			var unmarshaled Benchmark
+			if err := json.Unmarshal(data, &unmarshaled); err != nil {
+				t.Fatalf("unexpected error unmarshaling: %s", err)
+			}
+			if !reflect.DeepEqual(unmarshaled, bench) {
+				t.Errorf("unexpected benchmark after round trip\nexpected:\n%#v\nactual:\n%#v", bench, unmarshaled)
+			}
+		})
+	}
+}
+
+var benchVarValueJSONTests = map[string]struct {
+	varValue BenchVarValue
+}{
+	"int":            {varValue: BenchVarValue{Name: "size", Value: 2, position: 1}},
+	"integral_float": {varValue: BenchVarValue{Name: "delta", Value: 1.0, position: 2}},
+	"float":          {varValue: BenchVarValue{Name: "delta", Value: 0.001}},
+	"large_float":    {varValue: BenchVarValue{Name: "delta", Value: 1e21}},
+	"infinite_float": {varValue: BenchVarValue{Name: "delta", Value: math.Inf(-1)}},
+	"bool":           {varValue: BenchVarValue{Name: "abs_val", Value: false}},
+	"string":         {varValue: BenchVarValue{Name: "y", Value: "sin(x)"}},
+	"numeric_string": {varValue: BenchVarValue{Name: "y", Value: "2"}},
+	"uint64":         {varValue: BenchVarValue{Name: "size", Value: uint64(math.MaxUint64)}},
+	"int8":           {varValue: BenchVarValue{Name: "size", Value: int8(-3)}},
+	"float32":        {varValue: BenchVarValue{Name: "delta", Value: float32(0.1)}},
+	"duration":       {varValue: BenchVarValue{Name: "timeout", Value: 500 * time.Millisecond}},
+	"byte_size":      {varValue: BenchVarValue{Name: "size", Value: ByteSize(4096)}},
+	"min_int64":      {varValue: BenchVarValue{Name: "start_x", Value: int64(math.MinInt64)}},
+}
+
+func TestBenchVarValueJSON(t *testing.T) {
+	for testName, testCase := range benchVarValueJSONTests {
+		t.Run(testName, func(t *testing.T) {
+			data, err := json.Marshal(testCase.varValue)
+			if err != nil {
+				t.Fatalf("unexpected error marshaling: %s", err)
+			}
+			var unmarshaled BenchVarValue
+			if err := json.Unmarshal(data, &unmarshaled); err != nil {
+				t.Fatalf("unexpected error unmarshaling %s: %s", data, err)
+			}
+			if !reflect.DeepEqual(unmarshaled, testCase.varValue) {
+				t.Errorf("unexpected value after round trip of %s\nexpected:\n%#v\nactual:\n%#v", data, testCase.varValue, unmarshaled)
+			}
+		})
+	}
+}
+
+func TestBenchVarValueJSONNaN(t *testing.T) {
+	data, err := json.Marshal(BenchVarValue{Name: "delta", Value: math.NaN()})
+	if err != nil {
+		t.Fatalf("unexpected error marshaling: %s", err)
+	}
+	var unmarshaled BenchVarValue
+	if err := json.Unmarshal(data, &unmarshaled); err != nil {
+		t.Fatalf("unexpected error unmarshaling %s: %s", data, err)
+	}
+	if f, ok := unmarshaled.Value.(float64); !ok || !math.IsNaN(f) {
+		t.Errorf("unexpected value after round trip (expected=NaN, actual=%#v)", unmarshaled.Value)
+	}
+}
+
+func TestBenchVarValueJSONUnsupported(t *testing.T) {
+	if _, err := json.Marshal(BenchVarValue{Name: "sizes", Value: []int{1, 2}}); !errors.Is(err, errUnsupportedValue) {
+		t.Errorf("unexpected error marshaling (expected=%v, actual=%v)", errUnsupportedValue, err)
+	}
+
+	var unmarshaled BenchVarValue
+	err := json.Unmarshal([]byte(`{"name":"sizes","type":"slice","value":[1,2],"position":0}`), &unmarshaled)
+	if !errors.Is(err, errUnsupportedValue) {
+		t.Errorf("unexpected error unmarshaling (expected=%v, actual=%v)", errUnsupportedValue, err)
+	}
+}
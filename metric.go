@@ -0,0 +1,100 @@
+package benchparse
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Direction indicates whether higher or lower values of a metric are
+// better.
+type Direction int
+
+// The available directions.
+const (
+	UnknownDirection Direction = iota
+	LowerIsBetter
+	HigherIsBetter
+)
+
+func (d Direction) String() string {
+	switch d {
+	case UnknownDirection:
+		return "unknown"
+	case LowerIsBetter:
+		return "lower is better"
+	case HigherIsBetter:
+		return "higher is better"
+	default:
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+}
+
+// metricDirections are the directions of the standard metrics.
+var metricDirections = map[string]Direction{
+	"ns/op":     LowerIsBetter,
+	"MB/s":      HigherIsBetter,
+	"B/op":      LowerIsBetter,
+	"allocs/op": LowerIsBetter,
+}
+
+// customMetricInfo describes a custom metric registered with
+// RegisterMetric.
+type customMetricInfo struct {
+	dir  Direction
+	unit string
+}
+
+var (
+	customMetricsMu sync.RWMutex
+	customMetrics   = map[string]customMetricInfo{}
+)
+
+// RegisterMetric registers a custom metric (one reported via
+// testing.B.ReportMetric), where name is the unit the metric was
+// reported with (e.g. "hits/op"). dir is whether higher or lower
+// values of the metric are better and unit is the canonical unit of
+// its values (e.g. "ns" for a metric named "p99-ns"), which may be
+// empty.
+//
+// Once registered, the metric can be used anywhere a metric name is
+// accepted, such as thresholds, comparisons, and series, with results
+// which didn't report it treated as not measured. The header of the
+// metric's column in a Table also includes its unit.
+//
+// RegisterMetric panics if name is one of the standard metrics.
+func RegisterMetric(name string, dir Direction, unit string) {
+	if name == "N" || isOutputMetric(name) {
+		panic(fmt.Sprintf("benchparse: cannot register standard metric %s", name))
+	}
+	customMetricsMu.Lock()
+	defer customMetricsMu.Unlock()
+	customMetrics[name] = customMetricInfo{dir: dir, unit: unit}
+}
+
+func registeredMetric(name string) (customMetricInfo, bool) {
+	customMetricsMu.RLock()
+	defer customMetricsMu.RUnlock()
+	info, ok := customMetrics[name]
+	return info, ok
+}
+
+// MetricDirection returns whether higher or lower values of the named
+// metric are better, e.g. to color a change in the metric or to find
+// the best result. UnknownDirection is returned for the number of
+// iterations ("N") and for custom metrics which were not registered
+// with RegisterMetric.
+func MetricDirection(name string) Direction {
+	if dir, ok := metricDirections[name]; ok {
+		return dir
+	}
+	info, _ := registeredMetric(name)
+	return info.dir
+}
+
+// MetricUnit returns the canonical unit of a custom metric registered
+// with RegisterMetric, or an empty string if the metric wasn't
+// registered with a unit.
+func MetricUnit(name string) string {
+	info, _ := registeredMetric(name)
+	return info.unit
+}
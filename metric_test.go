@@ -0,0 +1,138 @@
+package benchparse
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var metricDirectionTests = map[string]struct {
+	metric   string
+	expected Direction
+}{
+	"ns_per_op":     {metric: "ns/op", expected: LowerIsBetter},
+	"mb_per_s":      {metric: "MB/s", expected: HigherIsBetter},
+	"b_per_op":      {metric: "B/op", expected: LowerIsBetter},
+	"allocs_per_op": {metric: "allocs/op", expected: LowerIsBetter},
+	"iterations":    {metric: "N", expected: UnknownDirection},
+	"custom":        {metric: "hits/op", expected: UnknownDirection},
+}
+
+func TestMetricDirection(t *testing.T) {
+	for testName, testCase := range metricDirectionTests {
+		t.Run(testName, func(t *testing.T) {
+			dir := MetricDirection(testCase.metric)
+			if dir != testCase.expected {
+				t.Errorf("unexpected direction (expected=%s, actual=%s)", testCase.expected, dir)
+			}
+		})
+	}
+}
+
+func TestRegisterMetric(t *testing.T) {
+	RegisterMetric("hits/op", HigherIsBetter, "hits")
+	defer func() {
+		customMetricsMu.Lock()
+		delete(customMetrics, "hits/op")
+		customMetricsMu.Unlock()
+	}()
+
+	if dir := MetricDirection("hits/op"); dir != HigherIsBetter {
+		t.Errorf("unexpected direction (expected=%s, actual=%s)", HigherIsBetter, dir)
+	}
+	if unit := MetricUnit("hits/op"); unit != "hits" {
+		t.Errorf("unexpected unit (expected=hits, actual=%s)", unit)
+	}
+
+	parseRun := func(input string) Benchmark {
+		t.Helper()
+		benches, err := ParseBenchmarks(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return benches[0]
+	}
+	var (
+		old = parseRun(`
+			BenchmarkCache/size=1-4 100 10 ns/op 2 hits/op
+			BenchmarkCache/size=2-4 100 10 ns/op 4 hits/op
+			BenchmarkCache/size=3-4 100 10 ns/op
+		`)
+		new = parseRun(`
+			BenchmarkCache/size=1-4 100 10 ns/op 4 hits/op
+			BenchmarkCache/size=2-4 100 10 ns/op 2 hits/op
+			BenchmarkCache/size=3-4 100 10 ns/op
+		`)
+	)
+
+	summary, err := CompareSummary(old, new, "hits/op", 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedSummary := Summary{
+		Improved:        1,
+		Regressed:       1,
+		ImprovedInputs:  []BenchInputs{new.Results[0].Inputs},
+		RegressedInputs: []BenchInputs{new.Results[1].Inputs},
+		UnchangedInputs: []BenchInputs{},
+		FromZeroInputs:  []BenchInputs{},
+		ChangedInputs:   []BenchInputs{},
+	}
+	if !reflect.DeepEqual(summary, expectedSummary) {
+		t.Errorf("unexpected summary\nexpected:\n%+v\nactual:\n%+v", expectedSummary, summary)
+	}
+
+	deltas, err := ResultDeltas(old, new, "hits/op")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expectedSpeedups := []string{"2.0x faster", "2.0x slower"}
+	for i, d := range deltas {
+		if s := d.Speedup(); s != expectedSpeedups[i] {
+			t.Errorf("unexpected speedup for delta %d (expected=%q, actual=%q)", i, expectedSpeedups[i], s)
+		}
+	}
+
+	missing := new.Results.MissingMetric("hits/op")
+	if !reflect.DeepEqual(missing, new.Results[2:]) {
+		t.Errorf("unexpected results missing metric\nexpected:\n%v\nactual:\n%v", new.Results[2:], missing)
+	}
+
+	filtered, err := new.Results.Filter("hits/op>3")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(filtered, new.Results[:1]) {
+		t.Errorf("unexpected filtered results\nexpected:\n%v\nactual:\n%v", new.Results[:1], filtered)
+	}
+
+	table := NewTable([]Benchmark{new})
+	expectedHeader := []string{"benchmark", "subs", "size", "procs", "iterations", "ns/op", "hits/op (hits)"}
+	if !reflect.DeepEqual(table.Header, expectedHeader) {
+		t.Errorf("unexpected table header\nexpected:\n%v\nactual:\n%v", expectedHeader, table.Header)
+	}
+	expectedRow := []string{"BenchmarkCache", "", "3", "4", "100", "10", ""}
+	if !reflect.DeepEqual(table.Rows[2], expectedRow) {
+		t.Errorf("unexpected table row\nexpected:\n%v\nactual:\n%v", expectedRow, table.Rows[2])
+	}
+}
+
+func TestRegisterStandardMetric(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("unexpectedly no panic")
+		}
+	}()
+	RegisterMetric("ns/op", HigherIsBetter, "ns")
+}
+
+func TestUnregisteredCustomMetric(t *testing.T) {
+	benches, err := ParseBenchmarks(strings.NewReader("BenchmarkFoo-4 100 10 ns/op 2 misses/op"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := benches[0].Results.ExceedsThreshold("misses/op", 1); !errors.Is(err, errUnknownMetric) {
+		t.Errorf("unexpected error (expected=%v, actual=%v)", errUnknownMetric, err)
+	}
+}
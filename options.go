@@ -0,0 +1,130 @@
+package benchparse
+
+import "strings"
+
+// ParseOption configures how benchmark output is parsed.
+type ParseOption func(*parseConfig)
+
+type parseConfig struct {
+	decimalComma bool
+	timestamps   bool
+	boolVars     map[string]bool
+
+	noDuplicateInputs bool
+	strict            bool // see ParseBenchmarksStrict
+}
+
+func newParseConfig(opts []ParseOption) parseConfig {
+	var cfg parseConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithDecimalComma handles output where numbers were formatted with a comma
+// as the decimal separator (e.g. '55,36 ns/op'), as can happen when output
+// passes through a localized formatter. Any periods in such numbers are
+// treated as thousands separators, so '1.234,5' is parsed as 1234.5.
+// Numbers without a comma, as well as the benchmark name, are left as is.
+//
+// The iteration count, B/op, and allocs/op are integers, so any commas
+// or periods in them are treated as thousands separators, e.g. both
+// '1,024 B/op' and '1.024 B/op' are parsed as 1024 B/op.
+func WithDecimalComma() ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.decimalComma = true
+	}
+}
+
+// WithTimestamps attaches the time each result was output to the
+// parsed results, which is available via BenchRes.Timestamp. This is
+// only available for output with the '-json' flag enabled, since
+// each event is timestamped. This can be used to e.g. detect
+// thermal throttling over the course of a run with '-count' set.
+func WithTimestamps() ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.timestamps = true
+	}
+}
+
+// WithBoolVars treats the named input variables as booleans, so values
+// of 1 and 0 are parsed as true and false respectively. This allows
+// e.g. 'enabled=1' and 'enabled=true' to be grouped and compared
+// consistently. Note that the String representation of such values
+// will be 'true' or 'false' rather than the original value.
+func WithBoolVars(names ...string) ParseOption {
+	return func(cfg *parseConfig) {
+		if cfg.boolVars == nil {
+			cfg.boolVars = map[string]bool{}
+		}
+		for _, name := range names {
+			cfg.boolVars[name] = true
+		}
+	}
+}
+
+// WithNoDuplicateInputs returns an error when a benchmark has multiple
+// results with the same inputs which aren't repetitions from running
+// with '-count', which usually indicates that logs were concatenated or
+// that the names of two benchmarks collided.
+//
+// Since testing.B runs each repetition of a case consecutively, results
+// with the same inputs are only treated as repetitions if no other case
+// of the benchmark was output in between. This means '-count' output can
+// still be parsed with this option, and the repetitions aggregated with
+// e.g. GroupedResults.Aggregate or checked with BenchResults.IsNoisy.
+func WithNoDuplicateInputs() ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.noDuplicateInputs = true
+	}
+}
+
+// coerceBoolVars converts the values of variables specified with
+// WithBoolVars to bools.
+func (cfg parseConfig) coerceBoolVars(varValues []BenchVarValue) {
+	if len(cfg.boolVars) == 0 {
+		return
+	}
+	for i, varVal := range varValues {
+		if !cfg.boolVars[varVal.Name] {
+			continue
+		}
+		switch varVal.Value {
+		case 1:
+			varValues[i].Value = true
+		case 0:
+			varValues[i].Value = false
+		}
+	}
+}
+
+// normalizeDecimalComma rewrites the numeric fields of a benchmark
+// line to use a period as the decimal separator, and removes the
+// thousands separators of integer fields.
+func normalizeDecimalComma(line string) string {
+	fields := strings.Fields(line)
+	// the first field is the benchmark name, followed by the iteration
+	// count and then pairs of values and units
+	if len(fields) > 1 {
+		fields[1] = removeSeparators(fields[1])
+	}
+	for i := 2; i+1 < len(fields); i += 2 {
+		switch {
+		case integerUnits[fields[i+1]]:
+			fields[i] = removeSeparators(fields[i])
+		case strings.Contains(fields[i], ","):
+			f := strings.Replace(fields[i], ".", "", -1)
+			fields[i] = strings.Replace(f, ",", ".", 1)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// integerUnits are the units of outputs which are always integers.
+var integerUnits = map[string]bool{"B/op": true, "allocs/op": true}
+
+// removeSeparators removes any thousands separators from an integer.
+func removeSeparators(s string) string {
+	return strings.NewReplacer(",", "", ".", "").Replace(s)
+}
@@ -0,0 +1,177 @@
+package benchparse
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+var decimalCommaTests = map[string]struct {
+	resultSet       string
+	expectedOutputs BenchOutputs
+}{
+	"ns_per_op": {
+		resultSet:       "BenchmarkFoo/y=f(a,b)-4 \t21801\t55,36 ns/op\t0 B/op\t0 allocs/op",
+		expectedOutputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkFoo/y=f(a,b)-4", N: 21801, NsPerOp: 55.36, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}},
+	},
+	"thousands_separator": {
+		resultSet:       "BenchmarkFoo/y=f(a,b)-4 \t21801\t1.234,5 ns/op\t6,33 MB/s",
+		expectedOutputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkFoo/y=f(a,b)-4", N: 21801, NsPerOp: 1234.5, MBPerS: 6.33, Measured: parse.NsPerOp | parse.MBPerS}},
+	},
+	"integer_outputs": {
+		resultSet:       "BenchmarkFoo/y=f(a,b)-4 \t21.801\t1,5 ns/op\t1,024 B/op\t2 allocs/op",
+		expectedOutputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkFoo/y=f(a,b)-4", N: 21801, NsPerOp: 1.5, AllocedBytesPerOp: 1024, AllocsPerOp: 2, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}},
+	},
+	"no_comma": {
+		resultSet:       "BenchmarkFoo/y=f(a,b)-4 \t21801\t13.3 ns/op",
+		expectedOutputs: parsedBenchOutputs{Benchmark: parse.Benchmark{Name: "BenchmarkFoo/y=f(a,b)-4", N: 21801, NsPerOp: 13.3, Measured: parse.NsPerOp}},
+	},
+}
+
+func TestWithDecimalComma(t *testing.T) {
+	for testName, testCase := range decimalCommaTests {
+		t.Run(testName, func(t *testing.T) {
+			benches, err := ParseBenchmarks(strings.NewReader(testCase.resultSet), WithDecimalComma())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(benches) != 1 || len(benches[0].Results) != 1 {
+				t.Fatalf("unexpected benchmarks: %v", benches)
+			}
+
+			res := benches[0].Results[0]
+			expectedInputs := BenchInputs{
+				VarValues: []BenchVarValue{{Name: "y", Value: "f(a,b)", position: 1}},
+				Subs:      []BenchSub{},
+				MaxProcs:  4,
+			}
+			if !reflect.DeepEqual(res.Inputs, expectedInputs) {
+				t.Errorf("unexpected inputs\nexpected:\n%#v\nactual:\n%#v", expectedInputs, res.Inputs)
+			}
+			testBenchResEq(t, BenchRes{Inputs: expectedInputs, Outputs: testCase.expectedOutputs}, res)
+		})
+	}
+}
+
+func TestWithTimestamps(t *testing.T) {
+	input := parseBenchmarksFromJSONTests["1_bench_4_cases_benchmem_set"].resultSet
+	benches, err := ParseBenchmarksFromJSON(strings.NewReader(input), WithTimestamps())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expectedTimestamps := []string{
+		"2020-05-13T22:50:49.609057-05:00",
+		"2020-05-13T22:57:01.992288-05:00",
+		"2020-05-13T22:57:01.994993-05:00",
+		"2020-05-13T22:57:01.997344-05:00",
+	}
+	if len(benches) != 1 || len(benches[0].Results) != len(expectedTimestamps) {
+		t.Fatalf("unexpected benchmarks: %v", benches)
+	}
+	for i, res := range benches[0].Results {
+		expected, err := time.Parse(time.RFC3339Nano, expectedTimestamps[i])
+		if err != nil {
+			t.Fatalf("unexpected error parsing expected timestamp: %s", err)
+		}
+		timestamp, ok := res.Timestamp()
+		if !ok || !timestamp.Equal(expected) {
+			t.Errorf("unexpected timestamp for result %d (expected=%s, actual=%s,%t)", i, expected, timestamp, ok)
+		}
+	}
+
+	benches, err = ParseBenchmarksFromJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, ok := benches[0].Results[0].Timestamp(); ok {
+		t.Errorf("unexpectedly timestamped without WithTimestamps")
+	}
+}
+
+func TestWithBoolVars(t *testing.T) {
+	input := `
+		BenchmarkFoo/enabled=1/count=1-4    100    10 ns/op
+		BenchmarkFoo/enabled=true/count=0-4 100    10 ns/op
+		BenchmarkFoo/enabled=0/count=1-4    100    10 ns/op
+		`
+	benches, err := ParseBenchmarks(strings.NewReader(input), WithBoolVars("enabled"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := [][]BenchVarValue{
+		{{Name: "enabled", Value: true, position: 1}, {Name: "count", Value: 1, position: 2}},
+		{{Name: "enabled", Value: true, position: 1}, {Name: "count", Value: 0, position: 2}},
+		{{Name: "enabled", Value: false, position: 1}, {Name: "count", Value: 1, position: 2}},
+	}
+	for i, res := range benches[0].Results {
+		if !reflect.DeepEqual(res.Inputs.VarValues, expected[i]) {
+			t.Errorf("unexpected var values for result %d\nexpected:\n%v\nactual:\n%v", i, expected[i], res.Inputs.VarValues)
+		}
+	}
+
+	grouped := benches[0].Results.Group([]string{"enabled"})
+	if len(grouped["enabled=true"]) != 2 || len(grouped["enabled=false"]) != 1 {
+		t.Errorf("unexpected grouped results: %v", grouped)
+	}
+}
+
+var noDuplicateInputsTests = map[string]struct {
+	resultSet   string
+	expectedErr error
+}{
+	"no_duplicates": {
+		resultSet: `
+			BenchmarkFoo/var=1-4 100 10 ns/op
+			BenchmarkFoo/var=2-4 100 10 ns/op
+			BenchmarkBar/var=1-4 100 10 ns/op
+			`,
+	},
+	"count_repetitions": {
+		resultSet: `
+			BenchmarkFoo/var=1-4 100 10 ns/op
+			BenchmarkFoo/var=1-4 100 11 ns/op
+			BenchmarkFoo/var=2-4 100 10 ns/op
+			BenchmarkFoo/var=2-4 100 11 ns/op
+			`,
+	},
+	"concatenated": {
+		resultSet: `
+			BenchmarkFoo/var=1-4 100 10 ns/op
+			BenchmarkFoo/var=2-4 100 10 ns/op
+			BenchmarkFoo/var=1-4 100 11 ns/op
+			`,
+		expectedErr: errDuplicateInputs,
+	},
+	"concatenated_count_repetitions": {
+		resultSet: `
+			BenchmarkFoo/var=1-4 100 10 ns/op
+			BenchmarkFoo/var=1-4 100 11 ns/op
+			BenchmarkFoo/var=1-4 100 10 ns/op
+			BenchmarkFoo/var=2-4 100 10 ns/op
+			BenchmarkFoo/var=1-4 100 10 ns/op
+			`,
+		expectedErr: errDuplicateInputs,
+	},
+}
+
+func TestWithNoDuplicateInputs(t *testing.T) {
+	for testName, testCase := range noDuplicateInputsTests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := ParseBenchmarks(strings.NewReader(testCase.resultSet), WithNoDuplicateInputs())
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+
+			// duplicates are allowed without the option
+			if _, err := ParseBenchmarks(strings.NewReader(testCase.resultSet)); err != nil {
+				t.Errorf("unexpected error without option: %s", err)
+			}
+		})
+	}
+}
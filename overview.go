@@ -0,0 +1,68 @@
+package benchparse
+
+import "sort"
+
+// SuiteOverview summarizes a set of parsed benchmarks.
+type SuiteOverview struct {
+	Benchmarks int // the number of benchmarks
+	Results    int // the total number of results across all benchmarks
+
+	// Metrics are the metrics measured by at least one result. The
+	// standard metrics are in the testing.B output order, followed by
+	// any custom metrics (those reported via testing.B.ReportMetric)
+	// sorted by unit.
+	Metrics []string
+
+	// Empty are the names of the benchmarks without any results.
+	Empty []string
+}
+
+// Overview returns the SuiteOverview of the provided benchmarks.
+func Overview(benches []Benchmark) SuiteOverview {
+	overview := SuiteOverview{
+		Benchmarks: len(benches),
+		Metrics:    measuredMetricNames(benches),
+		Empty:      []string{},
+	}
+	for _, bench := range benches {
+		if len(bench.Results) == 0 {
+			overview.Empty = append(overview.Empty, bench.Name)
+		}
+		overview.Results += len(bench.Results)
+	}
+	return overview
+}
+
+// measuredMetricNames returns the metrics measured by at least one
+// result of the benchmarks. The standard metrics are in the testing.B
+// output order, followed by any custom metrics sorted by unit.
+func measuredMetricNames(benches []Benchmark) []string {
+	var (
+		metrics  = []string{}
+		measured = map[string]bool{}
+		custom   = []string{}
+	)
+	for _, bench := range benches {
+		for _, res := range bench.Results {
+			for _, metric := range outputMetrics {
+				if _, err := metricValue(res.Outputs, metric); err == nil {
+					measured[metric] = true
+				}
+			}
+			for unit := range reportedMetrics(res.Outputs) {
+				if !measured[unit] {
+					measured[unit] = true
+					custom = append(custom, unit)
+				}
+			}
+		}
+	}
+
+	for _, metric := range outputMetrics {
+		if measured[metric] {
+			metrics = append(metrics, metric)
+		}
+	}
+	sort.Strings(custom)
+	return append(metrics, custom...)
+}
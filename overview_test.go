@@ -0,0 +1,59 @@
+package benchparse
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+var overviewTests = map[string]struct {
+	benches  []Benchmark
+	expected SuiteOverview
+}{
+	"sample": {
+		benches: []Benchmark{sampleBench},
+		expected: SuiteOverview{
+			Benchmarks: 1,
+			Results:    4,
+			Metrics:    []string{"ns/op", "B/op", "allocs/op"},
+			Empty:      []string{},
+		},
+	},
+	"empty_and_custom_metrics": {
+		benches: []Benchmark{
+			sampleBench,
+			{Name: "BenchmarkEmpty", Results: BenchResults{}},
+			{Name: "BenchmarkCustom", Results: BenchResults{
+				{Outputs: parsedBenchOutputs{
+					Benchmark: parse.Benchmark{N: 10, MBPerS: 5, Measured: parse.MBPerS},
+					extra:     map[string]float64{"p99-ns": 20, "hits/op": 2},
+				}},
+			}},
+		},
+		expected: SuiteOverview{
+			Benchmarks: 3,
+			Results:    5,
+			Metrics:    []string{"ns/op", "MB/s", "B/op", "allocs/op", "hits/op", "p99-ns"},
+			Empty:      []string{"BenchmarkEmpty"},
+		},
+	},
+	"no_benchmarks": {
+		benches: []Benchmark{},
+		expected: SuiteOverview{
+			Metrics: []string{},
+			Empty:   []string{},
+		},
+	},
+}
+
+func TestOverview(t *testing.T) {
+	for testName, testCase := range overviewTests {
+		t.Run(testName, func(t *testing.T) {
+			overview := Overview(testCase.benches)
+			if !reflect.DeepEqual(overview, testCase.expected) {
+				t.Errorf("unexpected overview\nexpected:\n%+v\nactual:\n%+v", testCase.expected, overview)
+			}
+		})
+	}
+}
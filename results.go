@@ -3,10 +3,12 @@ package benchparse
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/tools/benchmark/parse"
 )
@@ -17,6 +19,7 @@ type BenchVarValue struct {
 	Name     string
 	Value    interface{}
 	position int
+	text     string // the original text of Value, if rendered differently
 }
 
 func (b BenchVarValue) equal(o BenchVarValue) (bool, error) {
@@ -28,15 +31,11 @@ func (b BenchVarValue) equal(o BenchVarValue) (bool, error) {
 	k1, k2 := v1.Type().Kind(), v2.Type().Kind()
 
 	if isNumeric(k1) && isNumeric(k2) {
-		f1, err := getFloat(v1, k1)
+		c, err := compareNumeric(v1, v2)
 		if err != nil {
 			return false, err
 		}
-		f2, err := getFloat(v2, k2)
-		if err != nil {
-			return false, err
-		}
-		return f1 == f2, nil
+		return c == 0, nil
 	}
 	if k1 != k2 {
 		return false, errNonComparable
@@ -59,15 +58,11 @@ func (b BenchVarValue) less(o BenchVarValue) (bool, error) {
 	k1, k2 := v1.Type().Kind(), v2.Type().Kind()
 
 	if isNumeric(k1) && isNumeric(k2) {
-		f1, err := getFloat(v1, k1)
-		if err != nil {
-			return false, err
-		}
-		f2, err := getFloat(v2, k2)
+		c, err := compareNumeric(v1, v2)
 		if err != nil {
 			return false, err
 		}
-		return f1 < f2, nil
+		return c < 0, nil
 	}
 	if k1 != k2 {
 		return false, errNonComparable
@@ -81,6 +76,8 @@ func (b BenchVarValue) less(o BenchVarValue) (bool, error) {
 	}
 }
 
+// isNumeric reports whether values of the kind are numeric. This
+// includes time.Duration values, which are compared as nanoseconds.
 func isNumeric(k reflect.Kind) bool {
 	numericKinds := [...]reflect.Kind{
 		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
@@ -95,6 +92,89 @@ func isNumeric(k reflect.Kind) bool {
 	return false
 }
 
+// compareNumeric compares two numeric values, returning -1, 0, or 1 if
+// v1 is less than, equal to, or greater than v2 respectively. Integers
+// are compared exactly, since converting them to float64 loses precision
+// above 2^53, so only comparisons involving a float are approximate.
+func compareNumeric(v1, v2 reflect.Value) (int, error) {
+	k1, k2 := v1.Kind(), v2.Kind()
+	switch {
+	case isInt(k1) && isInt(k2):
+		return compareInts(v1.Int(), v2.Int()), nil
+	case isUint(k1) && isUint(k2):
+		return compareUints(v1.Uint(), v2.Uint()), nil
+	case isInt(k1) && isUint(k2):
+		if v1.Int() < 0 {
+			return -1, nil
+		}
+		return compareUints(uint64(v1.Int()), v2.Uint()), nil
+	case isUint(k1) && isInt(k2):
+		if v2.Int() < 0 {
+			return 1, nil
+		}
+		return compareUints(v1.Uint(), uint64(v2.Int())), nil
+	}
+
+	f1, err := getFloat(v1, k1)
+	if err != nil {
+		return 0, err
+	}
+	f2, err := getFloat(v2, k2)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case f1 < f2:
+		return -1, nil
+	case f1 > f2:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func compareInts(i1, i2 int64) int {
+	switch {
+	case i1 < i2:
+		return -1
+	case i1 > i2:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUints(u1, u2 uint64) int {
+	switch {
+	case u1 < u2:
+		return -1
+	case u1 > u2:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isInt(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isUint(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// getFloat returns the value of the numeric kind as a float64, with
+// time.Duration values converted to nanoseconds.
 func getFloat(v reflect.Value, k reflect.Kind) (float64, error) {
 	switch k {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -116,15 +196,41 @@ func getFloat(v reflect.Value, k reflect.Kind) (float64, error) {
 // precision and alternate string representations of various
 // types.
 //
-// Currently the '%f' verb is used for floating point values
-// in order to guarantee that they can be distinguished from
-// integer values. For everything else the default '%v' verb
-// is used for simplicities sake.
+// Floating point values are formatted in their shortest form which
+// re-parses to the same value, with '.0' appended to integral values
+// in order to guarantee that they can be distinguished from integer
+// values, so e.g. 0.001 is rendered as '0.001' and 1 as '1.0'. For
+// everything else the default '%v' verb
+// is used for simplicities sake, so a time.Duration value is
+// rendered as e.g. '500ms' and an integer as decimal. The exceptions
+// are a ByteSize, time.Duration, or int parsed from a benchmark name,
+// which are rendered as they were written (e.g. '1024KB' rather than
+// '1000KiB', '60s' rather than '1m0s', and '0xff' or '010' rather than
+// '255' or '10') so that the name is reproduced exactly.
 func (b BenchVarValue) String() string {
+	return b.Name + "=" + b.formatValue()
+}
+
+// formatValue returns the string representation of the value, see
+// String.
+func (b BenchVarValue) formatValue() string {
+	if b.text != "" {
+		return b.text
+	}
 	if f, ok := b.Value.(float64); ok {
-		return fmt.Sprintf("%s=%f", b.Name, f)
+		return formatFloatValue(f)
 	}
-	return fmt.Sprintf("%s=%v", b.Name, b.Value)
+	return fmt.Sprint(b.Value)
+}
+
+// formatFloatValue formats f compactly, but such that it is parsed as a
+// float rather than an int.
+func formatFloatValue(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
 }
 
 func (b BenchVarValue) pos() int {
@@ -143,6 +249,11 @@ func (b benchVarValues) String() string {
 
 // BenchSub represents an input to the benchmark represented
 // by a sub-benchmark with a name NOT of the form 'var_name=var_value'.
+//
+// Empty sub-benchmark names (e.g. the middle segment of
+// 'BenchmarkFoo//bar') are preserved as a BenchSub with an
+// empty Name so that the original benchmark name can be
+// reconstructed.
 type BenchSub struct {
 	Name     string
 	position int
@@ -203,6 +314,114 @@ func (b BenchInputs) String() string {
 	return s.String()
 }
 
+// SubPathVar is a reserved variable name which can be used with Group
+// and Filter to refer to the sub path of a result's inputs (see
+// BenchInputs.SubPath). An input variable with this name is ignored
+// when grouping or filtering by the sub path.
+const SubPathVar = "sub_path"
+
+// SubPath returns the names of the Subs joined by '/', in the order
+// they appear in the benchmark name. For example the sub path of
+// 'BenchmarkCache/with_eviction/size=2/hot_keys' is
+// 'with_eviction/hot_keys'.
+func (b BenchInputs) SubPath() string {
+	subs := b.sortedSubs()
+	names := make([]string, len(subs))
+	for i, sub := range subs {
+		names[i] = sub.Name
+	}
+	return strings.Join(names, "/")
+}
+
+// sortedSubs returns a copy of the Subs, sorted in the order they
+// appear in the benchmark name.
+func (b BenchInputs) sortedSubs() []BenchSub {
+	subs := make([]BenchSub, len(b.Subs))
+	copy(subs, b.Subs)
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].position < subs[j].position
+	})
+	return subs
+}
+
+// SubGroupPrefix is the prefix of a group name which refers to a
+// sub-benchmark name rather than an input variable when grouping with
+// Group. The prefix is followed by either the index of the sub among
+// the result's subs in the order they appear in the benchmark name
+// (e.g. 'sub:0' for the first sub) or by the name of a sub (e.g.
+// 'sub:areaUnder'), with a number always treated as an index.
+const SubGroupPrefix = "sub:"
+
+// subGroupValue returns the sub referred to by the group name as a
+// variable with the group name, see SubGroupPrefix. False is returned
+// if the group name doesn't refer to a sub or the inputs don't have the
+// referenced sub.
+func (b BenchInputs) subGroupValue(groupName string) (BenchVarValue, bool) {
+	ref := strings.TrimPrefix(groupName, SubGroupPrefix)
+	if ref == groupName {
+		return BenchVarValue{}, false
+	}
+	subs := b.sortedSubs()
+	if i, err := strconv.Atoi(ref); err == nil {
+		if i < 0 || i >= len(subs) {
+			return BenchVarValue{}, false
+		}
+		return BenchVarValue{Name: groupName, Value: subs[i].Name}, true
+	}
+	for _, sub := range subs {
+		if sub.Name == ref {
+			return BenchVarValue{Name: groupName, Value: sub.Name}, true
+		}
+	}
+	return BenchVarValue{}, false
+}
+
+// withSubPath returns the VarValues with the sub path in place of
+// any variable named SubPathVar.
+func (b BenchInputs) withSubPath() []BenchVarValue {
+	varValues := make([]BenchVarValue, 0, len(b.VarValues)+1)
+	varValues = append(varValues, BenchVarValue{Name: SubPathVar, Value: b.SubPath()})
+	for _, varVal := range b.VarValues {
+		if varVal.Name != SubPathVar {
+			varValues = append(varValues, varVal)
+		}
+	}
+	return varValues
+}
+
+// VarNames returns the names of the input variables.
+func (b BenchInputs) VarNames() []string {
+	names := make([]string, len(b.VarValues))
+	for i, varVal := range b.VarValues {
+		names[i] = varVal.Name
+	}
+	return names
+}
+
+// VarValue returns the input variable with the provided name, or false
+// if the inputs have no such variable.
+func (b BenchInputs) VarValue(name string) (BenchVarValue, bool) {
+	for _, varVal := range b.VarValues {
+		if varVal.Name == name {
+			return varVal, true
+		}
+	}
+	return BenchVarValue{}, false
+}
+
+// Value returns the value of the input variable with the provided name,
+// or false if the inputs have no such variable.
+func (b BenchInputs) Value(name string) (interface{}, bool) {
+	varVal, ok := b.VarValue(name)
+	return varVal.Value, ok
+}
+
+// key returns a string identifying the inputs, used to match
+// results with the same inputs across benchmarks.
+func (b BenchInputs) key() string {
+	return b.String()
+}
+
 // ErrNotMeasured indicates that a specific output
 // was not measured.
 var ErrNotMeasured = errors.New("not measured")
@@ -212,6 +431,12 @@ var ErrNotMeasured = errors.New("not measured")
 // Since not all output values are measured on each benchmark
 // run, the getter for these values will return ErrNotMeasured
 // if this is the case.
+//
+// Users may provide their own implementation, for example outputs
+// loaded from a database or parsed from a different format. All
+// functionality in this package accesses outputs only through
+// these methods and the optional interfaces which follow, so any
+// implementation can be used in a BenchRes.
 type BenchOutputs interface {
 	GetIterations() int
 	GetNsPerOp() (float64, error)
@@ -220,6 +445,59 @@ type BenchOutputs interface {
 	GetMBPerS() (float64, error)           // measured if testing.B.SetBytes() is called
 }
 
+// TotalAllocOutputs is implemented by BenchOutputs which provide the
+// total bytes allocated across all iterations, such as parsed outputs.
+// For outputs which don't implement it the total is computed from the
+// bytes allocated per iteration.
+type TotalAllocOutputs interface {
+	TotalAllocedBytes() (uint64, error) // the bytes allocated per iteration multiplied by the iterations
+}
+
+// totalAllocedBytes returns the total bytes allocated across all
+// iterations of b, see TotalAllocOutputs.
+func totalAllocedBytes(b BenchOutputs) (uint64, error) {
+	if t, ok := b.(TotalAllocOutputs); ok {
+		return t.TotalAllocedBytes()
+	}
+	perOp, err := b.GetAllocedBytesPerOp()
+	if err != nil {
+		return 0, err
+	}
+	return mulUint64(perOp, uint64(b.GetIterations()))
+}
+
+// CustomOutputs is implemented by BenchOutputs which include metrics
+// reported via testing.B.ReportMetric(). Outputs which don't implement
+// it are treated as having no custom metrics.
+type CustomOutputs interface {
+	GetCustom(unit string) (float64, error) // measured if testing.B.ReportMetric() is called with the unit
+	CustomMetrics() map[string]float64      // all metrics reported via testing.B.ReportMetric(), keyed by unit
+}
+
+// customValue returns the value of the custom metric with the provided
+// unit, see CustomOutputs.
+func customValue(b BenchOutputs, unit string) (float64, error) {
+	if c, ok := b.(CustomOutputs); ok {
+		return c.GetCustom(unit)
+	}
+	return 0, ErrNotMeasured
+}
+
+// reportedMetrics returns the custom metrics of b, see CustomOutputs.
+func reportedMetrics(b BenchOutputs) map[string]float64 {
+	if c, ok := b.(CustomOutputs); ok {
+		return c.CustomMetrics()
+	}
+	return nil
+}
+
+// RawOutputs is implemented by BenchOutputs backed by a parse.Benchmark,
+// providing access to fields not otherwise exposed, such as Ord or the
+// raw Measured bitmask.
+type RawOutputs interface {
+	Unwrap() parse.Benchmark // the underlying parsed result
+}
+
 func benchOutputsString(b BenchOutputs) string {
 	var s strings.Builder
 	s.WriteString(strconv.Itoa(b.GetIterations()))
@@ -235,13 +513,78 @@ func benchOutputsString(b BenchOutputs) string {
 	if allocsPerOp, err := b.GetAllocsPerOp(); err == nil {
 		fmt.Fprintf(&s, " %d allocs/op", allocsPerOp)
 	}
+	// testing.B reports custom metrics in the order they were
+	// reported, which can't be recovered, so sort by unit instead.
+	for _, m := range sortedCustomMetrics(b) {
+		fmt.Fprintf(&s, " %s %s", strconv.FormatFloat(m.value, 'f', -1, 64), m.unit)
+	}
 	return s.String()
 }
 
+// customMetric is a metric reported via testing.B.ReportMetric.
+type customMetric struct {
+	unit  string
+	value float64
+}
+
+// sortedCustomMetrics returns the custom metrics of b, sorted by unit.
+func sortedCustomMetrics(b BenchOutputs) []customMetric {
+	custom := reportedMetrics(b)
+	metrics := make([]customMetric, 0, len(custom))
+	for unit, value := range custom {
+		metrics = append(metrics, customMetric{unit: unit, value: value})
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].unit < metrics[j].unit
+	})
+	return metrics
+}
+
+// outputMetrics are the names of the standard measured outputs,
+// in the order they are reported by testing.B.
+var outputMetrics = []string{"ns/op", "MB/s", "B/op", "allocs/op"}
+
+var errUnknownMetric = errors.New("unknown metric")
+
+func isOutputMetric(name string) bool {
+	for _, metric := range outputMetrics {
+		if name == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// metricValue returns the value of the named metric. The metric
+// must either be "N" (the number of iterations), one of
+// outputMetrics, or a custom metric registered with RegisterMetric.
+func metricValue(b BenchOutputs, metric string) (float64, error) {
+	switch metric {
+	case "N":
+		return float64(b.GetIterations()), nil
+	case "ns/op":
+		return b.GetNsPerOp()
+	case "MB/s":
+		return b.GetMBPerS()
+	case "B/op":
+		v, err := b.GetAllocedBytesPerOp()
+		return float64(v), err
+	case "allocs/op":
+		v, err := b.GetAllocsPerOp()
+		return float64(v), err
+	default:
+		if _, ok := registeredMetric(metric); !ok {
+			return 0, fmt.Errorf("%w: %s", errUnknownMetric, metric)
+		}
+		return customValue(b, metric)
+	}
+}
+
 // parsedBenchOutputs wraps the parse.Benchmark type to
 // implement the BenchOutputs interface.
 type parsedBenchOutputs struct {
 	parse.Benchmark
+	extra map[string]float64 // custom metrics, keyed by unit
 }
 
 func (b parsedBenchOutputs) GetIterations() int {
@@ -293,11 +636,176 @@ func (b parsedBenchOutputs) GetMBPerS() (float64, error) {
 	return 0, ErrNotMeasured
 }
 
+// TotalAllocedBytes returns the total bytes allocated across all
+// iterations. This has more resolution than the bytes allocated
+// per iteration when comparing runs with different iteration counts.
+//
+// If the bytes allocated were not measured ErrNotMeasured is
+// returned, and if the total overflows a uint64 an error is returned.
+func (b parsedBenchOutputs) TotalAllocedBytes() (uint64, error) {
+	perOp, err := b.GetAllocedBytesPerOp()
+	if err != nil {
+		return 0, err
+	}
+	return mulUint64(perOp, uint64(b.N))
+}
+
+// GetCustom returns the value of the custom metric with the provided
+// unit, as reported via testing.B.ReportMetric().
+//
+// If not measured ErrNotMeasured is returned.
+func (b parsedBenchOutputs) GetCustom(unit string) (float64, error) {
+	if v, ok := b.extra[unit]; ok {
+		return v, nil
+	}
+	return 0, ErrNotMeasured
+}
+
+// CustomMetrics returns the custom metrics reported via
+// testing.B.ReportMetric(), keyed by unit. The returned map is a copy
+// and may be modified.
+func (b parsedBenchOutputs) CustomMetrics() map[string]float64 {
+	metrics := make(map[string]float64, len(b.extra))
+	for unit, v := range b.extra {
+		metrics[unit] = v
+	}
+	return metrics
+}
+
+// Unwrap returns the underlying parse.Benchmark.
+func (b parsedBenchOutputs) Unwrap() parse.Benchmark {
+	return b.Benchmark
+}
+
+// OutputOption sets an optional metric of the outputs constructed by
+// NewBenchOutputs.
+type OutputOption func(*parsedBenchOutputs)
+
+// NewBenchOutputs constructs the outputs of a benchmark run with the
+// provided number of iterations and nanoseconds per iteration, e.g. to
+// synthesize results for tests. Other metrics are not measured unless
+// set with the provided options.
+func NewBenchOutputs(iterations int, nsPerOp float64, opts ...OutputOption) BenchOutputs {
+	outputs := parsedBenchOutputs{Benchmark: parse.Benchmark{
+		N:        iterations,
+		NsPerOp:  nsPerOp,
+		Measured: parse.NsPerOp,
+	}}
+	for _, opt := range opts {
+		opt(&outputs)
+	}
+	return outputs
+}
+
+// WithMBPerS sets the MB processed per second, as measured when
+// testing.B.SetBytes() is called.
+func WithMBPerS(mbPerS float64) OutputOption {
+	return func(b *parsedBenchOutputs) {
+		b.MBPerS = mbPerS
+		b.Measured |= parse.MBPerS
+	}
+}
+
+// WithAllocs sets the bytes allocated and allocs per iteration, as
+// measured when '-test.benchmem' is set or testing.B.ReportAllocs() is
+// called.
+func WithAllocs(allocedBytesPerOp, allocsPerOp uint64) OutputOption {
+	return func(b *parsedBenchOutputs) {
+		b.AllocedBytesPerOp = allocedBytesPerOp
+		b.AllocsPerOp = allocsPerOp
+		b.Measured |= parse.AllocedBytesPerOp | parse.AllocsPerOp
+	}
+}
+
+// WithCustomMetric sets the value of a custom metric with the provided
+// unit, as reported via testing.B.ReportMetric().
+func WithCustomMetric(unit string, v float64) OutputOption {
+	return func(b *parsedBenchOutputs) {
+		if b.extra == nil {
+			b.extra = map[string]float64{}
+		}
+		b.extra[unit] = v
+	}
+}
+
 // BenchRes represents a result from a single benchmark run.
 // This corresponds to one line from the testing.B output.
 type BenchRes struct {
 	Inputs  BenchInputs  // the input variables
 	Outputs BenchOutputs // the output result
+
+	timestamp   time.Time
+	sampleIndex int
+}
+
+// Timestamp returns the time the result was output, which is only
+// available if parsed from JSON output with WithTimestamps.
+func (b BenchRes) Timestamp() (time.Time, bool) {
+	return b.timestamp, !b.timestamp.IsZero()
+}
+
+// SampleIndex returns the number of results with the same benchmark
+// name and inputs which preceded this result in the parsed output,
+// e.g. 0 for the first sample of a case when running with '-count' and
+// 1 for the second. This allows e.g. discarding the first sample of
+// each case as a warmup, see also SampleIndexVar.
+func (b BenchRes) SampleIndex() int {
+	return b.sampleIndex
+}
+
+// SampleIndexVar is a reserved variable name which can be used with
+// Group to group results by their sample index (see
+// BenchRes.SampleIndex). An input variable with this name is ignored
+// when grouping by the sample index.
+const SampleIndexVar = "sample_index"
+
+// withSampleIndex returns the varValues with the sample index of the
+// result in place of any variable named SampleIndexVar.
+func (b BenchRes) withSampleIndex(varValues []BenchVarValue) []BenchVarValue {
+	withIndex := make([]BenchVarValue, 0, len(varValues)+1)
+	withIndex = append(withIndex, BenchVarValue{Name: SampleIndexVar, Value: b.sampleIndex})
+	for _, varVal := range varValues {
+		if varVal.Name != SampleIndexVar {
+			withIndex = append(withIndex, varVal)
+		}
+	}
+	return withIndex
+}
+
+// BytesPerAlloc returns the mean size of each allocation, i.e. the
+// bytes allocated per iteration divided by the allocs per iteration.
+// This helps distinguish many small allocations from a few large ones.
+//
+// If either value was not measured ErrNotMeasured is returned. If
+// there were no allocations 0 is returned.
+func (b BenchRes) BytesPerAlloc() (float64, error) {
+	bytes, err := b.Outputs.GetAllocedBytesPerOp()
+	if err != nil {
+		return 0, err
+	}
+	allocs, err := b.Outputs.GetAllocsPerOp()
+	if err != nil {
+		return 0, err
+	}
+	if allocs == 0 {
+		return 0, nil
+	}
+	return float64(bytes) / float64(allocs), nil
+}
+
+// TotalNs returns the total time spent running the benchmark's
+// iterations in nanoseconds, i.e. the number of iterations multiplied
+// by the nanoseconds per iteration. This is useful for weighting
+// results with very different numbers of iterations.
+//
+// If the nanoseconds per iteration were not measured ErrNotMeasured
+// is returned.
+func (b BenchRes) TotalNs() (float64, error) {
+	nsPerOp, err := b.Outputs.GetNsPerOp()
+	if err != nil {
+		return 0, err
+	}
+	return float64(b.Outputs.GetIterations()) * nsPerOp, nil
 }
 
 // BenchResults represents a list of benchmark results
@@ -307,41 +815,451 @@ type BenchResults []BenchRes
 // the provided filter expr. For example filtering by the
 // expression 'var1<=2' will return the results where the
 // input variable named 'var1' has a value less than or
-// equal to 2.
+// equal to 2. Comparisons can be combined, for example
+// 'delta<1 && y==sin(x)', see ParseFilter for details.
+//
+// The output metrics can also be filtered on by name, for example
+// 'ns/op<1000' or 'allocs/op==0'. The names "N", "ns/op", "MB/s",
+// "B/op", "allocs/op", and those of custom metrics registered with
+// RegisterMetric refer to the metric, and results where the metric
+// was not measured are excluded. The exception is a result with an
+// input variable of the same name, for example 'N<=1000' compares the
+// variable of 'BenchmarkSort/N=1000' rather than its iterations.
 func (b BenchResults) Filter(filterExpr string) (BenchResults, error) {
-	varValCmp, err := parseValueComparison(filterExpr)
+	f, err := ParseFilter(filterExpr)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing %s: %w", filterExpr, err)
+		return nil, err
 	}
 
-	var (
-		filtered = []BenchRes{}
-		cmp      = varValCmp.cmp
-		value    = varValCmp.varValue
-	)
+	filtered := []BenchRes{}
+	for _, res := range b {
+		include, err := f.matches(res)
+		if err != nil {
+			return nil, err
+		}
+		if include {
+			filtered = append(filtered, res)
+		}
+	}
+	return filtered, nil
+}
 
+// FilterFunc returns the subset of the BenchResults for which keep
+// returns true, for filters which can't be expressed as a filter
+// expression. The receiver is not modified.
+func (b BenchResults) FilterFunc(keep func(BenchRes) bool) BenchResults {
+	filtered := []BenchRes{}
 	for _, res := range b {
-		for _, varVal := range res.Inputs.VarValues {
-			include, err := cmp.compare(varVal, value)
+		if keep(res) {
+			filtered = append(filtered, res)
+		}
+	}
+	return filtered
+}
+
+// Count returns the number of results matching the provided
+// filter expr, without allocating the filtered results.
+// See Filter for details on the filter expression.
+func (b BenchResults) Count(filterExpr string) (int, error) {
+	f, err := ParseFilter(filterExpr)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, res := range b {
+		include, err := f.matches(res)
+		if err != nil {
+			return 0, err
+		}
+		if include {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// First returns the first result, or false if there are no results.
+func (b BenchResults) First() (BenchRes, bool) {
+	if len(b) == 0 {
+		return BenchRes{}, false
+	}
+	return b[0], true
+}
+
+// Last returns the last result, or false if there are no results.
+func (b BenchResults) Last() (BenchRes, bool) {
+	if len(b) == 0 {
+		return BenchRes{}, false
+	}
+	return b[len(b)-1], true
+}
+
+// Sort sorts the results in place by key, in ascending order if asc is
+// true and descending order otherwise. The key is either the name of an
+// input variable, SubPathVar, or the name of a metric (see Filter), for
+// example 'ns/op'. Results without a value for the key, such as those
+// where the metric was not measured, are sorted to the end regardless
+// of the order. Values which cannot be compared are treated as equal.
+//
+// The sort is not guaranteed to be stable, see SortStable.
+func (b BenchResults) Sort(key string, asc bool) {
+	sort.Slice(b, b.sortLess(key, asc))
+}
+
+// SortStable sorts the results in place like Sort, while keeping the
+// original order of results with equal values for the key.
+func (b BenchResults) SortStable(key string, asc bool) {
+	sort.SliceStable(b, b.sortLess(key, asc))
+}
+
+// TopN returns the n results with the largest values of the named
+// metric if largest is true, or with the smallest values otherwise,
+// for example the 5 slowest cases by "ns/op". The results are returned
+// in order, with results with equal values kept in their original
+// order. Results where the metric was not measured are skipped, and all
+// remaining results are returned if there are fewer than n.
+//
+// The receiver is not modified.
+func (b BenchResults) TopN(metric string, n int, largest bool) (BenchResults, error) {
+	measured, err := b.filterMetric(metric, func(float64) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	measured.SortStable(metric, !largest)
+	if n < 0 {
+		n = 0
+	}
+	if n < len(measured) {
+		measured = measured[:n]
+	}
+	return measured, nil
+}
+
+func (b BenchResults) sortLess(key string, asc bool) func(i, j int) bool {
+	return func(i, j int) bool {
+		vi, iOk := b[i].sortValue(key)
+		vj, jOk := b[j].sortValue(key)
+		if !iOk || !jOk {
+			return iOk && !jOk
+		}
+		if !asc {
+			vi, vj = vj, vi
+		}
+		less, err := vi.less(vj)
+		return err == nil && less
+	}
+}
+
+// sortValue returns the value of the result for key, see Sort.
+func (b BenchRes) sortValue(key string) (BenchVarValue, bool) {
+	if b.isMetricKey(key) {
+		v, err := metricValue(b.Outputs, key)
+		return BenchVarValue{Name: key, Value: v}, err == nil
+	}
+	if key == SubPathVar {
+		return BenchVarValue{Name: key, Value: b.Inputs.SubPath()}, true
+	}
+	return b.Inputs.VarValue(key)
+}
+
+// Values returns the value of the named metric (see Filter) for each
+// result, in order. An error wrapping ErrNotMeasured is returned if the
+// metric was not measured for any of the results, see MissingMetric.
+func (b BenchResults) Values(metric string) ([]float64, error) {
+	values := make([]float64, len(b))
+	for i, res := range b {
+		v, err := metricValue(res.Outputs, metric)
+		if err != nil {
+			return nil, fmt.Errorf("result %d (%s): %w", i, res.Inputs, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// measuredValues returns the value of the metric for each result
+// where it was measured, in order.
+func (b BenchResults) measuredValues(metric string) ([]float64, error) {
+	values := make([]float64, 0, len(b))
+	for _, res := range b {
+		v, err := metricValue(res.Outputs, metric)
+		if err != nil {
+			if errors.Is(err, ErrNotMeasured) {
+				continue
+			}
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// Matrix returns the values of the provided metrics for each result,
+// for use with external numerical tools. Each row corresponds to a
+// result and each column to a metric, with NaN used for metrics
+// which weren't measured. The returned labels identify the row of
+// each result by its inputs, while the columns are labeled by the
+// provided metrics.
+func (b BenchResults) Matrix(metrics []string) (labels []string, rows [][]float64, err error) {
+	labels = make([]string, len(b))
+	rows = make([][]float64, len(b))
+	for i, res := range b {
+		labels[i] = res.Inputs.String()
+		row := make([]float64, len(metrics))
+		for j, metric := range metrics {
+			v, err := metricValue(res.Outputs, metric)
 			if err != nil {
-				if !errors.Is(err, errDifferentNames) {
-					return nil, err
+				if !errors.Is(err, ErrNotMeasured) {
+					return nil, nil, err
 				}
+				v = math.NaN()
+			}
+			row[j] = v
+		}
+		rows[i] = row
+	}
+	return labels, rows, nil
+}
+
+var errOverflow = errors.New("value overflows uint64")
+
+// TotalAllocedBytes returns the total bytes allocated across all
+// results, i.e. the sum of the TotalAllocedBytes of each result's
+// outputs. Results where the bytes allocated were not measured are
+// skipped, and ErrNotMeasured is returned if it was not measured
+// for any result.
+func (b BenchResults) TotalAllocedBytes() (uint64, error) {
+	return b.sum(totalAllocedBytes)
+}
+
+// TotalAllocs returns the total number of allocations across all
+// results, i.e. the sum of the allocs per iteration multiplied by
+// the number of iterations. Results where the allocs were not
+// measured are skipped, and ErrNotMeasured is returned if it was
+// not measured for any result.
+func (b BenchResults) TotalAllocs() (uint64, error) {
+	return b.sum(func(o BenchOutputs) (uint64, error) {
+		perOp, err := o.GetAllocsPerOp()
+		if err != nil {
+			return 0, err
+		}
+		return mulUint64(perOp, uint64(o.GetIterations()))
+	})
+}
+
+// sum returns the sum of the value returned by get for each
+// result, skipping those which weren't measured.
+func (b BenchResults) sum(get func(BenchOutputs) (uint64, error)) (uint64, error) {
+	var (
+		total    uint64
+		measured bool
+	)
+	for _, res := range b {
+		v, err := get(res.Outputs)
+		if err != nil {
+			if errors.Is(err, ErrNotMeasured) {
 				continue
 			}
-			if include {
-				filtered = append(filtered, res)
-				break
+			return 0, err
+		}
+		measured = true
+
+		if total+v < total {
+			return 0, errOverflow
+		}
+		total += v
+	}
+	if !measured {
+		return 0, ErrNotMeasured
+	}
+	return total, nil
+}
+
+func mulUint64(a, b uint64) (uint64, error) {
+	if a != 0 && b > math.MaxUint64/a {
+		return 0, errOverflow
+	}
+	return a * b, nil
+}
+
+// ExceedsThreshold returns the results where the named metric is
+// above limit, e.g. to check that no case takes more than 1ms/op
+// regardless of any baseline. Results where the metric was not
+// measured are skipped.
+func (b BenchResults) ExceedsThreshold(metric string, limit float64) (BenchResults, error) {
+	return b.compareMetric(metric, Gt, limit)
+}
+
+// BelowThreshold returns the results where the named metric is below
+// limit, e.g. to check that no case has a throughput less than 100MB/s.
+// Results where the metric was not measured are skipped.
+func (b BenchResults) BelowThreshold(metric string, limit float64) (BenchResults, error) {
+	return b.compareMetric(metric, Lt, limit)
+}
+
+// compareMetric returns the results where the named metric satisfies the
+// comparison with limit, skipping results where it was not measured.
+func (b BenchResults) compareMetric(metric string, c Comparison, limit float64) (BenchResults, error) {
+	var cmpErr error
+	filtered, err := b.filterMetric(metric, func(v float64) bool {
+		ok, err := c.CompareFloat(v, limit)
+		if err != nil && cmpErr == nil {
+			cmpErr = err
+		}
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cmpErr != nil {
+		return nil, cmpErr
+	}
+	return filtered, nil
+}
+
+func (b BenchResults) filterMetric(metric string, include func(v float64) bool) (BenchResults, error) {
+	filtered := []BenchRes{}
+	for _, res := range b {
+		v, err := metricValue(res.Outputs, metric)
+		if err != nil {
+			if errors.Is(err, ErrNotMeasured) {
+				continue
 			}
+			return nil, err
+		}
+		if include(v) {
+			filtered = append(filtered, res)
 		}
 	}
 	return filtered, nil
 }
 
+// MissingMetric returns the results where the named metric was not
+// measured, e.g. to find benchmarks which don't call b.SetBytes or
+// b.ReportAllocs. Since only the standard metrics and those registered
+// with RegisterMetric can be missing, no results are returned for an
+// unknown metric name.
+func (b BenchResults) MissingMetric(metric string) BenchResults {
+	missing := []BenchRes{}
+	for _, res := range b {
+		if _, err := metricValue(res.Outputs, metric); errors.Is(err, ErrNotMeasured) {
+			missing = append(missing, res)
+		}
+	}
+	return missing
+}
+
+// Intersect returns the results with inputs present in both b and
+// other. Results are matched by their inputs rather than their outputs,
+// so the results from b are returned. Each distinct input is included
+// at most once, using the first matching result.
+func (b BenchResults) Intersect(other BenchResults) BenchResults {
+	otherKeys := make(map[string]bool, len(other))
+	for _, res := range other {
+		otherKeys[res.Inputs.key()] = true
+	}
+
+	var (
+		intersection = []BenchRes{}
+		seen         = map[string]bool{}
+	)
+	for _, res := range b {
+		k := res.Inputs.key()
+		if otherKeys[k] && !seen[k] {
+			seen[k] = true
+			intersection = append(intersection, res)
+		}
+	}
+	return intersection
+}
+
+// Union returns the results with inputs present in either b or other.
+// Each distinct input is included at most once, using the first result
+// with that input from b followed by other.
+func (b BenchResults) Union(other BenchResults) BenchResults {
+	var (
+		union = []BenchRes{}
+		seen  = map[string]bool{}
+	)
+	for _, results := range []BenchResults{b, other} {
+		for _, res := range results {
+			k := res.Inputs.key()
+			if !seen[k] {
+				seen[k] = true
+				union = append(union, res)
+			}
+		}
+	}
+	return union
+}
+
+// DistinctValues returns the distinct values of the named input
+// variable across the results, sorted in ascending order. Values
+// for which ordering is not defined (e.g. bools) are returned in
+// the order they first appear. An error is returned if the values
+// cannot be compared with each other.
+func (b BenchResults) DistinctValues(varName string) ([]interface{}, error) {
+	distinct := []BenchVarValue{}
+	for _, res := range b {
+		for _, varVal := range res.Inputs.VarValues {
+			if varVal.Name != varName {
+				continue
+			}
+			found := false
+			for _, d := range distinct {
+				eq, err := d.equal(varVal)
+				if err != nil {
+					return nil, compareErr{val1: d, val2: varVal, comparison: Eq, err: err}
+				}
+				if eq {
+					found = true
+					break
+				}
+			}
+			if !found {
+				distinct = append(distinct, varVal)
+			}
+		}
+	}
+
+	var sortErr error
+	sort.SliceStable(distinct, func(i, j int) bool {
+		if sortErr != nil {
+			return false
+		}
+		less, err := distinct[i].less(distinct[j])
+		if err != nil {
+			if !errors.Is(err, errOperationNotDefined) {
+				sortErr = compareErr{val1: distinct[i], val2: distinct[j], comparison: Lt, err: err}
+			}
+			return false
+		}
+		return less
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	values := make([]interface{}, len(distinct))
+	for i, d := range distinct {
+		values[i] = d.Value
+	}
+	return values, nil
+}
+
 // Group groups a benchmarks results by a specified set of
 // input variable names. For example a Benchmark with Results corresponding
 // to the cases [/foo=1/bar=baz /foo=2/bar=baz /foo=1/bar=qux /foo=2/bar=qux]
 // grouped by ['foo'] would have 2 groups of results (those with Inputs where
+// foo=1 and those where foo=2).
+//
+// Results can also be grouped by their sub-benchmark names using group
+// names with SubGroupPrefix, for example grouping the cases
+// [/areaUnder/foo=1 /max/foo=1 /areaUnder/foo=2] by ['sub:0'] would
+// have 2 groups (those with the first sub 'areaUnder' and those with
+// 'max'). As with variables, results without the referenced sub are
+// skipped.
 func (b BenchResults) Group(groupBy []string) GroupedResults {
 	groupedResults := map[string]BenchResults{}
 	if len(groupBy) == 0 {
@@ -350,15 +1268,36 @@ func (b BenchResults) Group(groupBy []string) GroupedResults {
 		groupedResults[""] = res
 		return groupedResults
 	}
+	bySubPath, bySampleIndex := false, false
+	for _, groupName := range groupBy {
+		switch groupName {
+		case SubPathVar:
+			bySubPath = true
+		case SampleIndexVar:
+			bySampleIndex = true
+		}
+	}
 	for _, result := range b {
 		groupVals := benchVarValues{}
-		for _, varValue := range result.Inputs.VarValues {
+		varValues := result.Inputs.VarValues
+		if bySubPath {
+			varValues = result.Inputs.withSubPath()
+		}
+		if bySampleIndex {
+			varValues = result.withSampleIndex(varValues)
+		}
+		for _, varValue := range varValues {
 			for _, groupName := range groupBy {
 				if varValue.Name == groupName {
 					groupVals = append(groupVals, varValue)
 				}
 			}
 		}
+		for _, groupName := range groupBy {
+			if subVal, ok := result.Inputs.subGroupValue(groupName); ok {
+				groupVals = append(groupVals, subVal)
+			}
+		}
 		if len(groupVals) != len(groupBy) {
 			continue
 		}
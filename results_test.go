@@ -1,13 +1,16 @@
 package benchparse
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"reflect"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/tools/benchmark/parse"
 )
@@ -53,6 +56,275 @@ func testBenchResEq(t *testing.T, expected, actual BenchRes) {
 	}
 }
 
+// testRes returns a result with the inputs which only measured the
+// named metric, with the value.
+func testRes(inputs BenchInputs, metric string, value float64) BenchRes {
+	outputs := parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100}}
+	switch metric {
+	case "ns/op":
+		outputs.NsPerOp, outputs.Measured = value, parse.NsPerOp
+	case "B/op":
+		outputs.AllocedBytesPerOp, outputs.Measured = uint64(value), parse.AllocedBytesPerOp
+	case "allocs/op":
+		outputs.AllocsPerOp, outputs.Measured = uint64(value), parse.AllocsPerOp
+	case "MB/s":
+		outputs.MBPerS, outputs.Measured = value, parse.MBPerS
+	default:
+		outputs.extra = map[string]float64{metric: value}
+	}
+	return BenchRes{Inputs: inputs, Outputs: outputs}
+}
+
+// withMetric returns b with a result for each of the values of the
+// named metric, using the inputs of the results of b in order and
+// starting over from the first once they run out.
+func withMetric(b Benchmark, metric string, values ...float64) Benchmark {
+	results := make(BenchResults, len(values))
+	for i, v := range values {
+		results[i] = testRes(b.Results[i%len(b.Results)].Inputs, metric, v)
+	}
+	return Benchmark{Name: b.Name, Results: results}
+}
+
+// sizeResults returns a result for each pair of the value of a "size"
+// variable and the value of the named metric.
+func sizeResults(metric string, sizesAndValues ...float64) BenchResults {
+	results := make(BenchResults, len(sizesAndValues)/2)
+	for i := range results {
+		inputs := BenchInputs{VarValues: []BenchVarValue{{Name: "size", Value: sizesAndValues[i*2], position: 1}}}
+		results[i] = testRes(inputs, metric, sizesAndValues[i*2+1])
+	}
+	return results
+}
+
+// customOutputs is a BenchOutputs implementation not backed by
+// parse.Benchmark.
+type customOutputs struct {
+	iterations int
+	nsPerOp    float64
+}
+
+func (c customOutputs) GetIterations() int                    { return c.iterations }
+func (c customOutputs) GetNsPerOp() (float64, error)          { return c.nsPerOp, nil }
+func (c customOutputs) GetAllocedBytesPerOp() (uint64, error) { return 0, ErrNotMeasured }
+func (c customOutputs) GetAllocsPerOp() (uint64, error)       { return 0, ErrNotMeasured }
+func (c customOutputs) GetMBPerS() (float64, error)           { return 0, ErrNotMeasured }
+
+func TestCustomBenchOutputs(t *testing.T) {
+	bench := Benchmark{
+		Name: "BenchmarkFoo",
+		Results: []BenchRes{
+			{
+				Inputs:  BenchInputs{VarValues: []BenchVarValue{{Name: "size", Value: 1, position: 1}}, MaxProcs: 1},
+				Outputs: customOutputs{iterations: 100, nsPerOp: 20},
+			},
+			{
+				Inputs:  BenchInputs{VarValues: []BenchVarValue{{Name: "size", Value: 2, position: 1}}, MaxProcs: 1},
+				Outputs: customOutputs{iterations: 50, nsPerOp: 40},
+			},
+		},
+	}
+
+	expectedString := "BenchmarkFoo/size=1 100 20.00 ns/op\nBenchmarkFoo/size=2 50 40.00 ns/op"
+	if s := bench.String(); s != expectedString {
+		t.Errorf("unexpected string\nexpected:\n%s\nactual:\n%s", expectedString, s)
+	}
+
+	expectedRows := [][]string{
+		{"BenchmarkFoo", "", "1", "1", "100", "20"},
+		{"BenchmarkFoo", "", "2", "1", "50", "40"},
+	}
+	if table := NewTable([]Benchmark{bench}); !reflect.DeepEqual(table.Rows, expectedRows) {
+		t.Errorf("unexpected table rows\nexpected:\n%v\nactual:\n%v", expectedRows, table.Rows)
+	}
+
+	filtered, err := bench.Results.Filter("size>1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(filtered, BenchResults{bench.Results[1]}) {
+		t.Errorf("unexpected filtered results: %v", filtered)
+	}
+
+	summary, err := CompareSummary(bench, bench, "ns/op", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if summary.Unchanged != 2 {
+		t.Errorf("unexpected number of unchanged cases (expected=2, actual=%d)", summary.Unchanged)
+	}
+
+	if _, err := bench.Results.TotalAllocedBytes(); !errors.Is(err, ErrNotMeasured) {
+		t.Errorf("unexpected error\nexpected=%s\nactual=%v", ErrNotMeasured, err)
+	}
+
+	// outputs without custom metrics or a parse.Benchmark can still be
+	// persisted and merged
+	if _, err := json.Marshal(bench); err != nil {
+		t.Errorf("unexpected error marshaling: %s", err)
+	}
+	if merged := bench.Results.Merge(); len(merged) != 2 {
+		t.Errorf("unexpected number of merged results (expected=2, actual=%d)", len(merged))
+	}
+}
+
+func TestSubPath(t *testing.T) {
+	benches, err := ParseBenchmarks(strings.NewReader("BenchmarkCache/with_eviction/size=2/hot_keys-4 100 10 ns/op"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if subPath := benches[0].Results[0].Inputs.SubPath(); subPath != "with_eviction/hot_keys" {
+		t.Errorf("unexpected sub path (expected=with_eviction/hot_keys, actual=%s)", subPath)
+	}
+}
+
+func TestVarValue(t *testing.T) {
+	inputs := sampleBench.Results[0].Inputs
+
+	varVal, ok := inputs.VarValue("y")
+	if !ok {
+		t.Fatalf("unexpectedly no variable y")
+	}
+	if varVal.Name != "y" || varVal.Value != "sin(x)" {
+		t.Errorf("unexpected variable: %s", varVal)
+	}
+
+	if v, ok := inputs.Value("start_x"); !ok || v != -2 {
+		t.Errorf("unexpected start_x (expected=-2, actual=%v, ok=%t)", v, ok)
+	}
+	if v, ok := inputs.Value("missing"); ok {
+		t.Errorf("unexpectedly found missing variable: %v", v)
+	}
+}
+
+func TestNumericValues(t *testing.T) {
+	tests := map[string]interface{}{
+		"1e6":    1e6,
+		"0xff":   255,
+		"0XFF":   255,
+		"-0b101": -5,
+		"0o17":   15,
+		"010":    10,
+		"0B":     ByteSize(0),
+		"0xg":    "0xg",
+	}
+	for s, expected := range tests {
+		t.Run(s, func(t *testing.T) {
+			if v := value(s); v != expected {
+				t.Errorf("unexpected value (expected=%#v, actual=%#v)", expected, v)
+			}
+		})
+	}
+}
+
+func TestFloatValueString(t *testing.T) {
+	tests := map[float64]string{
+		0.001:   "delta=0.001",
+		1:       "delta=1.0",
+		-2.5:    "delta=-2.5",
+		1e21:    "delta=1e+21",
+		0.1 / 3: "delta=0.03333333333333333",
+	}
+	for f, expected := range tests {
+		v := BenchVarValue{Name: "delta", Value: f}
+		if s := v.String(); s != expected {
+			t.Errorf("unexpected string (expected=%s, actual=%s)", expected, s)
+		}
+		// the string should re-parse to the same value
+		if parsed := value(strings.TrimPrefix(expected, "delta=")); parsed != f {
+			t.Errorf("unexpected value after round trip of %s (expected=%#v, actual=%#v)", expected, f, parsed)
+		}
+	}
+}
+
+func TestLargeIntegerValues(t *testing.T) {
+	benches, err := ParseBenchmarks(strings.NewReader(`
+BenchmarkRand/seed=18446744073709551615-4 100 10 ns/op
+BenchmarkRand/seed=18446744073709551614-4 100 20 ns/op
+BenchmarkRand/seed=9007199254740993-4 100 30 ns/op
+BenchmarkRand/seed=9007199254740992-4 100 40 ns/op
+BenchmarkRand/seed=-1-4 100 50 ns/op
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	results := benches[0].Results
+
+	if v := results[0].Inputs.VarValues[0].Value; v != uint64(18446744073709551615) {
+		t.Errorf("unexpected value (expected=%#v, actual=%#v)", uint64(18446744073709551615), v)
+	}
+
+	filters := map[string]BenchResults{
+		"seed==18446744073709551615": results[:1],
+		"seed<18446744073709551615":  results[1:],
+		"seed==9007199254740993":     results[2:3],
+		"seed>9007199254740992":      results[:3],
+		"seed<0":                     results[4:],
+		"seed>=1.5":                  results[:4],
+	}
+	for expr, expected := range filters {
+		filtered, err := results.Filter(expr)
+		if err != nil {
+			t.Fatalf("unexpected error filtering by %s: %s", expr, err)
+		}
+		if !reflect.DeepEqual(filtered, expected) {
+			t.Errorf("unexpected results filtering by %s\nexpected:\n%v\nactual:\n%v", expr, expected, filtered)
+		}
+	}
+}
+
+func TestBenchVarValueStringRoundTrip(t *testing.T) {
+	for _, s := range []string{"1", "-2", "0", "0.5", "1.000000", "1e6", "2.5E-3", "0xff", "-0b101", "0o17", "010", "true", "false", "True", "FALSE", "foo", "2x+3", "500ms", "1h30m", "-1.5s"} {
+		t.Run(s, func(t *testing.T) {
+			parsed := BenchVarValue{Name: "var", Value: value(s)}
+			reparsed := BenchVarValue{Name: "var", Value: value(strings.TrimPrefix(parsed.String(), "var="))}
+			if !reflect.DeepEqual(parsed, reparsed) {
+				t.Errorf("value not preserved by String()\noriginal:\n%#v\nreparsed:\n%#v", parsed, reparsed)
+			}
+		})
+	}
+}
+
+func TestDurationValues(t *testing.T) {
+	benches, err := ParseBenchmarks(strings.NewReader(`
+BenchmarkPoll/interval=1s/timeout=500ms-4 100 10 ns/op
+BenchmarkPoll/interval=1s/timeout=2s-4 100 20 ns/op
+BenchmarkPoll/interval=100ms/timeout=1m0s-4 100 30 ns/op
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	results := benches[0].Results
+
+	timeout := results[0].Inputs.VarValues[1]
+	if timeout.Value != 500*time.Millisecond {
+		t.Errorf("unexpected value (expected=%#v, actual=%#v)", 500*time.Millisecond, timeout.Value)
+	}
+	if s := timeout.String(); s != "timeout=500ms" {
+		t.Errorf("unexpected string (expected=timeout=500ms, actual=%s)", s)
+	}
+
+	filters := map[string]BenchResults{
+		"timeout<1s":         results[:1],
+		"timeout>=2s":        results[1:],
+		"interval==1000ms":   results[:2],
+		"timeout<1000000000": results[:1],
+	}
+	for expr, expected := range filters {
+		filtered, err := results.Filter(expr)
+		if err != nil {
+			t.Fatalf("unexpected error filtering by %s: %s", expr, err)
+		}
+		if !reflect.DeepEqual(filtered, expected) {
+			t.Errorf("unexpected results filtering by %s\nexpected:\n%v\nactual:\n%v", expr, expected, filtered)
+		}
+	}
+
+	if _, err := results.Filter("timeout==fast"); !errors.Is(err, errNonComparable) {
+		t.Errorf("unexpected error (expected=%v, actual=%v)", errNonComparable, err)
+	}
+}
+
 var getOutputMeasurementTests = map[string]struct {
 	output                       parsedBenchOutputs
 	expectedNsPerOp              float64
@@ -65,7 +337,7 @@ var getOutputMeasurementTests = map[string]struct {
 	expectedMBPerSErr            error
 }{
 	"all_set": {
-		output: parsedBenchOutputs{parse.Benchmark{
+		output: parsedBenchOutputs{Benchmark: parse.Benchmark{
 			N:                 21801,
 			NsPerOp:           55357,
 			AllocedBytesPerOp: 4321,
@@ -79,7 +351,7 @@ var getOutputMeasurementTests = map[string]struct {
 		expectedMBPerS:            0.12,
 	},
 	"benchmem_not_set_with_set_bytes": {
-		output: parsedBenchOutputs{parse.Benchmark{
+		output: parsedBenchOutputs{Benchmark: parse.Benchmark{
 			N:        21801,
 			NsPerOp:  55357,
 			MBPerS:   0.12,
@@ -91,7 +363,7 @@ var getOutputMeasurementTests = map[string]struct {
 		expectedMBPerS:               0.12,
 	},
 	"benchmem_set_but_no_allocs": {
-		output: parsedBenchOutputs{parse.Benchmark{
+		output: parsedBenchOutputs{Benchmark: parse.Benchmark{
 			N:                 21801,
 			NsPerOp:           55357,
 			AllocedBytesPerOp: 0,
@@ -103,6 +375,18 @@ var getOutputMeasurementTests = map[string]struct {
 		expectedAllocsPerOp:       0,
 		expectedMBPerSErr:         ErrNotMeasured,
 	},
+	"allocs_without_ns_per_op": {
+		output: parsedBenchOutputs{Benchmark: parse.Benchmark{
+			N:                 21801,
+			AllocedBytesPerOp: 4321,
+			AllocsPerOp:       21,
+			Measured:          parse.AllocedBytesPerOp | parse.AllocsPerOp,
+		}},
+		expectedNsPerOpErr:        ErrNotMeasured,
+		expectedAllocedBytesPerOp: 4321,
+		expectedAllocsPerOp:       21,
+		expectedMBPerSErr:         ErrNotMeasured,
+	},
 	"none_set": {
 		output:                       parsedBenchOutputs{},
 		expectedNsPerOpErr:           ErrNotMeasured,
@@ -131,6 +415,134 @@ func TestGetOutputMeasumentTests(t *testing.T) {
 	}
 }
 
+var totalAllocedBytesTests = map[string]struct {
+	output        parsedBenchOutputs
+	expectedTotal uint64
+	expectedErr   error
+}{
+	"measured": {
+		output:        parsedBenchOutputs{Benchmark: parse.Benchmark{N: 21801, AllocedBytesPerOp: 3, Measured: parse.AllocedBytesPerOp}},
+		expectedTotal: 65403,
+	},
+	"not_measured": {
+		output:      parsedBenchOutputs{Benchmark: parse.Benchmark{N: 21801, NsPerOp: 3, Measured: parse.NsPerOp}},
+		expectedErr: ErrNotMeasured,
+	},
+	"overflow": {
+		output:      parsedBenchOutputs{Benchmark: parse.Benchmark{N: 3, AllocedBytesPerOp: math.MaxUint64 / 2, Measured: parse.AllocedBytesPerOp}},
+		expectedErr: errOverflow,
+	},
+}
+
+func TestTotalAllocedBytes(t *testing.T) {
+	for testName, testCase := range totalAllocedBytesTests {
+		t.Run(testName, func(t *testing.T) {
+			total, err := testCase.output.TotalAllocedBytes()
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if total != testCase.expectedTotal {
+				t.Errorf("unexpected total (expected=%d, actual=%d)", testCase.expectedTotal, total)
+			}
+		})
+	}
+}
+
+var getCustomTests = map[string]struct {
+	output          parsedBenchOutputs
+	unit            string
+	expectedV       float64
+	expectedErr     error
+	expectedMetrics map[string]float64
+}{
+	"measured": {
+		output:          parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100}, extra: map[string]float64{"hits/op": 0.9, "items/op": 4.5}},
+		unit:            "items/op",
+		expectedV:       4.5,
+		expectedMetrics: map[string]float64{"hits/op": 0.9, "items/op": 4.5},
+	},
+	"other_unit_measured": {
+		output:          parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100}, extra: map[string]float64{"hits/op": 0.9}},
+		unit:            "items/op",
+		expectedErr:     ErrNotMeasured,
+		expectedMetrics: map[string]float64{"hits/op": 0.9},
+	},
+	"none_measured": {
+		output:          parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, NsPerOp: 3, Measured: parse.NsPerOp}},
+		unit:            "ns/op",
+		expectedErr:     ErrNotMeasured,
+		expectedMetrics: map[string]float64{},
+	},
+}
+
+func TestGetCustom(t *testing.T) {
+	for testName, testCase := range getCustomTests {
+		t.Run(testName, func(t *testing.T) {
+			v, err := testCase.output.GetCustom(testCase.unit)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if v != testCase.expectedV {
+				t.Errorf("unexpected value (expected=%v, actual=%v)", testCase.expectedV, v)
+			}
+
+			metrics := testCase.output.CustomMetrics()
+			if !reflect.DeepEqual(metrics, testCase.expectedMetrics) {
+				t.Errorf("unexpected custom metrics\nexpected:\n%v\nactual:\n%v", testCase.expectedMetrics, metrics)
+			}
+			for unit := range metrics {
+				metrics[unit] = -1
+			}
+			if v, _ := testCase.output.GetCustom(testCase.unit); v != testCase.expectedV {
+				t.Errorf("modifying custom metrics changed outputs (expected=%v, actual=%v)", testCase.expectedV, v)
+			}
+		})
+	}
+}
+
+func TestNewBenchOutputs(t *testing.T) {
+	bench := Benchmark{
+		Name: "BenchmarkFoo",
+		Results: []BenchRes{
+			{
+				Inputs:  BenchInputs{VarValues: []BenchVarValue{{Name: "size", Value: 1, position: 1}}, MaxProcs: 1},
+				Outputs: NewBenchOutputs(100, 20),
+			},
+			{
+				Inputs:  BenchInputs{VarValues: []BenchVarValue{{Name: "size", Value: 2, position: 1}}, MaxProcs: 1},
+				Outputs: NewBenchOutputs(50, 40, WithMBPerS(1.5), WithAllocs(16, 1), WithCustomMetric("hits/op", 3), WithCustomMetric("misses/op", 0.5)),
+			},
+		},
+	}
+
+	expectedString := "BenchmarkFoo/size=1 100 20.00 ns/op\nBenchmarkFoo/size=2 50 40.00 ns/op 1.50 MB/s 16 B/op 1 allocs/op 3 hits/op 0.5 misses/op"
+	if s := bench.String(); s != expectedString {
+		t.Errorf("unexpected string\nexpected:\n%s\nactual:\n%s", expectedString, s)
+	}
+
+	parsed, err := ParseBenchmarks(strings.NewReader(expectedString))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bench.Equal(parsed[0]) {
+		t.Errorf("constructed benchmark not equal to parsed\nconstructed:\n%s\nparsed:\n%s", bench, parsed[0])
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	benches, err := ParseBenchmarks(strings.NewReader("BenchmarkFoo/size=1-4 100 10 ns/op 0 B/op 0 allocs/op"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	raw := benches[0].Results[0].Outputs.(RawOutputs).Unwrap()
+	if raw.Name != "BenchmarkFoo/size=1-4" || raw.N != 100 {
+		t.Errorf("unexpected parsed benchmark: %+v", raw)
+	}
+	if expected := parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp; raw.Measured != expected {
+		t.Errorf("unexpected measured (expected=%b, actual=%b)", expected, raw.Measured)
+	}
+}
+
 func testNsPerOp(t *testing.T, b parsedBenchOutputs, expectedV float64, expectedErr error) {
 	t.Helper()
 	ns, err := b.GetNsPerOp()
@@ -257,16 +669,16 @@ var groupResultsTests = map[string]struct {
 		benchmark: sampleBench,
 		groupBy:   []string{"y", "delta"},
 		expectedGroupedResults: map[string]BenchResults{
-			"y=sin(x),delta=0.001000": []BenchRes{
+			"y=sin(x),delta=0.001": []BenchRes{
 				sampleBench.Results[0],
 			},
-			"y=2x+3,delta=1.000000": []BenchRes{
+			"y=2x+3,delta=1.0": []BenchRes{
 				sampleBench.Results[1],
 			},
-			"y=2x+3,delta=0.001000": []BenchRes{
+			"y=2x+3,delta=0.001": []BenchRes{
 				sampleBench.Results[2],
 			},
-			"y=sin(x),delta=1.000000": []BenchRes{
+			"y=sin(x),delta=1.0": []BenchRes{
 				sampleBench.Results[3],
 			},
 		},
@@ -283,6 +695,55 @@ var groupResultsTests = map[string]struct {
 			},
 		},
 	},
+	"group_by_sub_path": {
+		benchmark: sampleBench,
+		groupBy:   []string{SubPathVar, "y"},
+		expectedGroupedResults: map[string]BenchResults{
+			"sub_path=areaUnder,y=sin(x)": []BenchRes{
+				sampleBench.Results[0],
+			},
+			"sub_path=areaUnder,y=2x+3": []BenchRes{
+				sampleBench.Results[1],
+			},
+			"sub_path=max,y=2x+3": []BenchRes{
+				sampleBench.Results[2],
+			},
+			"sub_path=max,y=sin(x)": []BenchRes{
+				sampleBench.Results[3],
+			},
+		},
+	},
+	"group_by_sub_index": {
+		benchmark: sampleBench,
+		groupBy:   []string{"sub:0"},
+		expectedGroupedResults: map[string]BenchResults{
+			"sub:0=areaUnder": []BenchRes{
+				sampleBench.Results[0],
+				sampleBench.Results[1],
+			},
+			"sub:0=max": []BenchRes{
+				sampleBench.Results[2],
+				sampleBench.Results[3],
+			},
+		},
+	},
+	"group_by_sub_name_and_var": {
+		benchmark: sampleBench,
+		groupBy:   []string{"sub:max", "y"},
+		expectedGroupedResults: map[string]BenchResults{
+			"y=2x+3,sub:max=max": []BenchRes{
+				sampleBench.Results[2],
+			},
+			"y=sin(x),sub:max=max": []BenchRes{
+				sampleBench.Results[3],
+			},
+		},
+	},
+	"group_by_missing_sub_index": {
+		benchmark:              sampleBench,
+		groupBy:                []string{"sub:1"},
+		expectedGroupedResults: map[string]BenchResults{},
+	},
 }
 
 func TestGroupResults(t *testing.T) {
@@ -321,15 +782,9 @@ func ExampleBenchResults_Group() {
 
 	for _, k := range groupNames {
 		fmt.Println(k)
-		v := groupedResults[k]
-
-		times := make([]float64, len(v))
-		for i, res := range v {
-			nsPerOp, err := res.Outputs.GetNsPerOp()
-			if err != nil {
-				log.Fatal(err)
-			}
-			times[i] = nsPerOp
+		times, err := groupedResults[k].Values("ns/op")
+		if err != nil {
+			log.Fatal(err)
 		}
 		fmt.Printf("ns per op = %v\n", times)
 	}
@@ -340,6 +795,13 @@ func ExampleBenchResults_Group() {
 	// ns per op = [55357 62.7]
 }
 
+// sizeVarResults are results with an input variable named "N", as
+// for e.g. 'BenchmarkSort/N=1000'.
+var sizeVarResults = BenchResults{
+	testRes(BenchInputs{VarValues: []BenchVarValue{{Name: "N", Value: 1000}}}, "ns/op", 50),
+	testRes(BenchInputs{VarValues: []BenchVarValue{{Name: "N", Value: 10}}}, "ns/op", 5),
+}
+
 var filterTests = map[string]struct {
 	results          BenchResults
 	filterExpr       string
@@ -366,11 +828,151 @@ var filterTests = map[string]struct {
 		filterExpr:  "y==2",
 		expectedErr: errNonComparable,
 	},
+	"filter_by_sub_path": {
+		results:          sampleBench.Results,
+		filterExpr:       "sub_path==max",
+		expectedFiltered: BenchResults{sampleBench.Results[2], sampleBench.Results[3]},
+	},
+	"filter_by_match": {
+		results:          sampleBench.Results,
+		filterExpr:       "y=~sin",
+		expectedFiltered: BenchResults{sampleBench.Results[0], sampleBench.Results[3]},
+	},
+	"filter_by_case_insensitive_match": {
+		results:          sampleBench.Results,
+		filterExpr:       "y=~(?i)^SIN",
+		expectedFiltered: BenchResults{sampleBench.Results[0], sampleBench.Results[3]},
+	},
+	"filter_by_not_match": {
+		results:          sampleBench.Results,
+		filterExpr:       "y!~x\\+",
+		expectedFiltered: BenchResults{sampleBench.Results[0], sampleBench.Results[3]},
+	},
+	"filter_by_sub_path_match": {
+		results:          sampleBench.Results,
+		filterExpr:       "sub_path=~^area",
+		expectedFiltered: BenchResults{sampleBench.Results[0], sampleBench.Results[1]},
+	},
+	"match_non_string_value": {
+		results:     sampleBench.Results,
+		filterExpr:  "delta=~1",
+		expectedErr: errNonComparable,
+	},
+	"filter_by_bool": {
+		results:          sampleBench.Results,
+		filterExpr:       "abs_val==true",
+		expectedFiltered: BenchResults{sampleBench.Results[0]},
+	},
+	"filter_by_bool_as_int": {
+		results:          sampleBench.Results,
+		filterExpr:       "abs_val==1",
+		expectedFiltered: BenchResults{sampleBench.Results[0]},
+	},
+	"filter_by_bool_ne_int": {
+		results:          sampleBench.Results,
+		filterExpr:       "abs_val!=0",
+		expectedFiltered: BenchResults{sampleBench.Results[0]},
+	},
+	"filter_by_bool_non_bool_int": {
+		results:     sampleBench.Results,
+		filterExpr:  "abs_val==2",
+		expectedErr: errNonComparable,
+	},
+	"filter_by_bool_lt": {
+		results:     sampleBench.Results,
+		filterExpr:  "abs_val<1",
+		expectedErr: errOperationNotDefined,
+	},
 	"invalid_filter_expr": {
 		results:     sampleBench.Results,
 		filterExpr:  "y,2",
 		expectedErr: errMalformedFilter,
 	},
+	"filter_by_ns_per_op": {
+		results:          sampleBench.Results,
+		filterExpr:       "ns/op>5000",
+		expectedFiltered: BenchResults{sampleBench.Results[0], sampleBench.Results[2]},
+	},
+	"filter_by_allocs_per_op": {
+		results:          sampleBench.Results,
+		filterExpr:       "allocs/op==0",
+		expectedFiltered: sampleBench.Results,
+	},
+	"filter_by_iterations": {
+		results:          sampleBench.Results,
+		filterExpr:       "N>=1000000",
+		expectedFiltered: BenchResults{sampleBench.Results[1], sampleBench.Results[3]},
+	},
+	"filter_by_unmeasured_metric": {
+		results:          sampleBench.Results,
+		filterExpr:       "MB/s>0",
+		expectedFiltered: BenchResults{},
+	},
+	"filter_by_metric_and_var": {
+		results:          sampleBench.Results,
+		filterExpr:       "ns/op<100 && y==sin(x)",
+		expectedFiltered: BenchResults{sampleBench.Results[3]},
+	},
+	"and": {
+		results:          sampleBench.Results,
+		filterExpr:       "delta<1 && y==sin(x)",
+		expectedFiltered: BenchResults{sampleBench.Results[0]},
+	},
+	"or": {
+		results:          sampleBench.Results,
+		filterExpr:       "delta<1||sub_path==max",
+		expectedFiltered: BenchResults{sampleBench.Results[0], sampleBench.Results[2], sampleBench.Results[3]},
+	},
+	"parenthesized": {
+		results:          sampleBench.Results,
+		filterExpr:       "(start_x>=0 || end_x<=1) && abs_val==true",
+		expectedFiltered: BenchResults{sampleBench.Results[0]},
+	},
+	"and_binds_tighter_than_or": {
+		results:          sampleBench.Results,
+		filterExpr:       "y==sin(x) || delta<1 && start_x==-1",
+		expectedFiltered: BenchResults{sampleBench.Results[0], sampleBench.Results[3]},
+	},
+	"parentheses_override_precedence": {
+		results:          sampleBench.Results,
+		filterExpr:       "(y==sin(x) || delta<1) && start_x==-1",
+		expectedFiltered: BenchResults{sampleBench.Results[3]},
+	},
+	"short_circuit": {
+		results:          sampleBench.Results,
+		filterExpr:       "delta>1 && y==2",
+		expectedFiltered: BenchResults{},
+	},
+	"compound_non_comparable_values": {
+		results:     sampleBench.Results,
+		filterExpr:  "delta<1 && y==2",
+		expectedErr: errNonComparable,
+	},
+	"missing_operand": {
+		results:     sampleBench.Results,
+		filterExpr:  "delta<1 &&",
+		expectedErr: errMalformedFilter,
+	},
+	"unclosed_parenthesis": {
+		results:     sampleBench.Results,
+		filterExpr:  "(delta<1 || y==sin(x)",
+		expectedErr: errMalformedFilter,
+	},
+	"unopened_parenthesis": {
+		results:     sampleBench.Results,
+		filterExpr:  "delta<1) || y==sin(x)",
+		expectedErr: errMalformedFilter,
+	},
+	"var_named_like_metric": {
+		results:          sizeVarResults,
+		filterExpr:       "N==1000",
+		expectedFiltered: BenchResults{sizeVarResults[0]},
+	},
+	"var_named_like_metric_not_iterations": {
+		results:          sizeVarResults,
+		filterExpr:       "N==100",
+		expectedFiltered: BenchResults{},
+	},
 }
 
 func TestFilter(t *testing.T) {
@@ -391,6 +993,591 @@ func TestFilter(t *testing.T) {
 	}
 }
 
+func TestFilterFunc(t *testing.T) {
+	var (
+		results  = append(BenchResults{}, sampleBench.Results...)
+		filtered = results.FilterFunc(func(res BenchRes) bool {
+			nsPerOp, err := res.Outputs.GetNsPerOp()
+			return err == nil && nsPerOp < 100
+		})
+	)
+	if expected := (BenchResults{sampleBench.Results[1], sampleBench.Results[3]}); !reflect.DeepEqual(filtered, expected) {
+		t.Errorf("unexpected filtered results\nexpected:\n%v\nactual:\n%v", expected, filtered)
+	}
+	if !reflect.DeepEqual(results, sampleBench.Results) {
+		t.Errorf("results unexpectedly modified: %v", results)
+	}
+
+	if none := results.FilterFunc(func(BenchRes) bool { return false }); none == nil || len(none) != 0 {
+		t.Errorf("unexpected filtered results: %#v", none)
+	}
+}
+
+func TestCount(t *testing.T) {
+	for testName, testCase := range filterTests {
+		t.Run(testName, func(t *testing.T) {
+			count, err := testCase.results.Count(testCase.filterExpr)
+			if err != nil {
+				if !errors.Is(err, testCase.expectedErr) {
+					t.Errorf("unexpected error\nexpected=%s\nactual=%s", testCase.expectedErr, err)
+				}
+				return
+			}
+
+			if count != len(testCase.expectedFiltered) {
+				t.Errorf("unexpected count (expected=%d, actual=%d)", len(testCase.expectedFiltered), count)
+			}
+		})
+	}
+}
+
+var thresholdTests = map[string]struct {
+	results         BenchResults
+	metric          string
+	limit           float64
+	expectedExceeds BenchResults
+	expectedBelow   BenchResults
+	expectedErr     error
+}{
+	"ns_per_op": {
+		results:         sampleBench.Results,
+		metric:          "ns/op",
+		limit:           1000,
+		expectedExceeds: BenchResults{sampleBench.Results[0], sampleBench.Results[2]},
+		expectedBelow:   BenchResults{sampleBench.Results[1], sampleBench.Results[3]},
+	},
+	"not_measured": {
+		results:         sampleBench.Results,
+		metric:          "MB/s",
+		limit:           1000,
+		expectedExceeds: BenchResults{},
+		expectedBelow:   BenchResults{},
+	},
+	"unknown_metric": {
+		results:     sampleBench.Results,
+		metric:      "foo/op",
+		expectedErr: errUnknownMetric,
+	},
+}
+
+func TestThreshold(t *testing.T) {
+	for testName, testCase := range thresholdTests {
+		t.Run(testName, func(t *testing.T) {
+			exceeds, err := testCase.results.ExceedsThreshold(testCase.metric, testCase.limit)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("unexpected ExceedsThreshold() error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if !reflect.DeepEqual(exceeds, testCase.expectedExceeds) {
+				t.Errorf("unexpected ExceedsThreshold() results\nexpected:\n%v\nactual:\n%v", testCase.expectedExceeds, exceeds)
+			}
+
+			below, err := testCase.results.BelowThreshold(testCase.metric, testCase.limit)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("unexpected BelowThreshold() error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if !reflect.DeepEqual(below, testCase.expectedBelow) {
+				t.Errorf("unexpected BelowThreshold() results\nexpected:\n%v\nactual:\n%v", testCase.expectedBelow, below)
+			}
+		})
+	}
+}
+
+var bytesPerAllocTests = map[string]struct {
+	output      parsedBenchOutputs
+	expected    float64
+	expectedErr error
+}{
+	"allocs": {
+		output:   parsedBenchOutputs{Benchmark: parse.Benchmark{AllocedBytesPerOp: 4096, AllocsPerOp: 8, Measured: parse.AllocedBytesPerOp | parse.AllocsPerOp}},
+		expected: 512,
+	},
+	"fractional": {
+		output:   parsedBenchOutputs{Benchmark: parse.Benchmark{AllocedBytesPerOp: 10, AllocsPerOp: 4, Measured: parse.AllocedBytesPerOp | parse.AllocsPerOp}},
+		expected: 2.5,
+	},
+	"no_allocs": {
+		output:   parsedBenchOutputs{Benchmark: parse.Benchmark{Measured: parse.AllocedBytesPerOp | parse.AllocsPerOp}},
+		expected: 0,
+	},
+	"not_measured": {
+		output:      parsedBenchOutputs{Benchmark: parse.Benchmark{NsPerOp: 10, Measured: parse.NsPerOp}},
+		expectedErr: ErrNotMeasured,
+	},
+}
+
+func TestSampleIndex(t *testing.T) {
+	benches, err := ParseBenchmarks(strings.NewReader(`
+BenchmarkFoo/size=1-4 100 10 ns/op
+BenchmarkFoo/size=1-4 100 11 ns/op
+BenchmarkFoo/size=2-4 100 20 ns/op
+BenchmarkBar/size=1-4 100 30 ns/op
+BenchmarkFoo/size=2-4 100 21 ns/op
+BenchmarkFoo/size=1-4 100 12 ns/op
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var (
+		results  = benches[0].Results
+		expected = []int{0, 1, 0, 1, 2}
+	)
+	for i, res := range results {
+		if res.SampleIndex() != expected[i] {
+			t.Errorf("unexpected sample index of result %d (expected=%d, actual=%d)", i, expected[i], res.SampleIndex())
+		}
+	}
+	if i := benches[1].Results[0].SampleIndex(); i != 0 {
+		t.Errorf("unexpected sample index of other benchmark (expected=0, actual=%d)", i)
+	}
+
+	grouped := results.Group([]string{SampleIndexVar})
+	expectedGrouped := GroupedResults{
+		"sample_index=0": {results[0], results[2]},
+		"sample_index=1": {results[1], results[3]},
+		"sample_index=2": {results[4]},
+	}
+	if !reflect.DeepEqual(grouped, expectedGrouped) {
+		t.Errorf("unexpected grouped results\nexpected:\n%v\nactual:\n%v", expectedGrouped, grouped)
+	}
+
+	withoutWarmup := results.FilterFunc(func(res BenchRes) bool { return res.SampleIndex() > 0 })
+	if !reflect.DeepEqual(withoutWarmup, BenchResults{results[1], results[3], results[4]}) {
+		t.Errorf("unexpected results without warmup: %v", withoutWarmup)
+	}
+}
+
+func TestBytesPerAlloc(t *testing.T) {
+	for testName, testCase := range bytesPerAllocTests {
+		t.Run(testName, func(t *testing.T) {
+			v, err := BenchRes{Outputs: testCase.output}.BytesPerAlloc()
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if v != testCase.expected {
+				t.Errorf("unexpected bytes per alloc (expected=%v, actual=%v)", testCase.expected, v)
+			}
+		})
+	}
+}
+
+func TestTotalNs(t *testing.T) {
+	total, err := sampleBench.Results[1].TotalNs()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := 88335925 * 13.3; total != expected {
+		t.Errorf("unexpected total (expected=%v, actual=%v)", expected, total)
+	}
+
+	res := BenchRes{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, AllocsPerOp: 1, Measured: parse.AllocsPerOp}}}
+	if _, err := res.TotalNs(); !errors.Is(err, ErrNotMeasured) {
+		t.Errorf("unexpected error (expected=%v, actual=%v)", ErrNotMeasured, err)
+	}
+}
+
+func TestFirstLast(t *testing.T) {
+	first, ok := sampleBench.Results.First()
+	if !ok || !reflect.DeepEqual(first, sampleBench.Results[0]) {
+		t.Errorf("unexpected first result (ok=%t)\nexpected:\n%v\nactual:\n%v", ok, sampleBench.Results[0], first)
+	}
+	last, ok := sampleBench.Results.Last()
+	if !ok || !reflect.DeepEqual(last, sampleBench.Results[3]) {
+		t.Errorf("unexpected last result (ok=%t)\nexpected:\n%v\nactual:\n%v", ok, sampleBench.Results[3], last)
+	}
+
+	if _, ok := (BenchResults{}).First(); ok {
+		t.Errorf("unexpectedly found first of empty results")
+	}
+	if _, ok := (BenchResults(nil)).Last(); ok {
+		t.Errorf("unexpectedly found last of nil results")
+	}
+}
+
+var notMeasuredNsPerOp = BenchRes{
+	Inputs:  BenchInputs{MaxProcs: 1},
+	Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, AllocsPerOp: 1, Measured: parse.AllocsPerOp}},
+}
+
+var sortTests = map[string]struct {
+	results  BenchResults
+	key      string
+	asc      bool
+	stable   bool
+	expected BenchResults
+}{
+	"metric_asc": {
+		results:  sampleBench.Results,
+		key:      "ns/op",
+		asc:      true,
+		expected: BenchResults{sampleBench.Results[1], sampleBench.Results[3], sampleBench.Results[2], sampleBench.Results[0]},
+	},
+	"metric_desc": {
+		results:  sampleBench.Results,
+		key:      "ns/op",
+		expected: BenchResults{sampleBench.Results[0], sampleBench.Results[2], sampleBench.Results[3], sampleBench.Results[1]},
+	},
+	"float_var_asc": {
+		results:  sampleBench.Results,
+		key:      "delta",
+		asc:      true,
+		stable:   true,
+		expected: BenchResults{sampleBench.Results[0], sampleBench.Results[2], sampleBench.Results[1], sampleBench.Results[3]},
+	},
+	"var_named_like_metric": {
+		results:  sizeVarResults,
+		key:      "N",
+		asc:      true,
+		expected: BenchResults{sizeVarResults[1], sizeVarResults[0]},
+	},
+	"int_var_desc": {
+		results:  sampleBench.Results,
+		key:      "start_x",
+		stable:   true,
+		expected: BenchResults{sampleBench.Results[1], sampleBench.Results[3], sampleBench.Results[0], sampleBench.Results[2]},
+	},
+	"string_var_asc": {
+		results:  sampleBench.Results,
+		key:      "y",
+		asc:      true,
+		stable:   true,
+		expected: BenchResults{sampleBench.Results[1], sampleBench.Results[2], sampleBench.Results[0], sampleBench.Results[3]},
+	},
+	"sub_path_desc": {
+		results:  sampleBench.Results,
+		key:      SubPathVar,
+		stable:   true,
+		expected: BenchResults{sampleBench.Results[2], sampleBench.Results[3], sampleBench.Results[0], sampleBench.Results[1]},
+	},
+	"missing_var_asc": {
+		results:  BenchResults{sampleBench.Results[2], sampleBench.Results[1], sampleBench.Results[0]},
+		key:      "abs_val",
+		asc:      true,
+		stable:   true,
+		expected: BenchResults{sampleBench.Results[1], sampleBench.Results[0], sampleBench.Results[2]},
+	},
+	"not_measured_asc": {
+		results:  BenchResults{sampleBench.Results[2], notMeasuredNsPerOp, sampleBench.Results[1]},
+		key:      "ns/op",
+		asc:      true,
+		expected: BenchResults{sampleBench.Results[1], sampleBench.Results[2], notMeasuredNsPerOp},
+	},
+	"not_measured_desc": {
+		results:  BenchResults{notMeasuredNsPerOp, sampleBench.Results[1], sampleBench.Results[2]},
+		key:      "ns/op",
+		expected: BenchResults{sampleBench.Results[2], sampleBench.Results[1], notMeasuredNsPerOp},
+	},
+}
+
+func TestSort(t *testing.T) {
+	for testName, testCase := range sortTests {
+		t.Run(testName, func(t *testing.T) {
+			sorted := make(BenchResults, len(testCase.results))
+			copy(sorted, testCase.results)
+			if testCase.stable {
+				sorted.SortStable(testCase.key, testCase.asc)
+			} else {
+				sorted.Sort(testCase.key, testCase.asc)
+			}
+
+			if !reflect.DeepEqual(sorted, testCase.expected) {
+				t.Errorf("unexpected sorted results\nexpected:\n%v\nactual:\n%v", testCase.expected, sorted)
+			}
+		})
+	}
+}
+
+var missingMetricTests = map[string]struct {
+	results  BenchResults
+	metric   string
+	expected BenchResults
+}{
+	"none_measured": {
+		results:  sampleBench.Results,
+		metric:   "MB/s",
+		expected: sampleBench.Results,
+	},
+	"all_measured": {
+		results:  sampleBench.Results,
+		metric:   "ns/op",
+		expected: BenchResults{},
+	},
+	"some_measured": {
+		results: BenchResults{
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 10, NsPerOp: 5, AllocsPerOp: 1, Measured: parse.NsPerOp | parse.AllocsPerOp}}},
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 10, NsPerOp: 5, Measured: parse.NsPerOp}}},
+		},
+		metric: "allocs/op",
+		expected: BenchResults{
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 10, NsPerOp: 5, Measured: parse.NsPerOp}}},
+		},
+	},
+	"unknown_metric": {
+		results:  sampleBench.Results,
+		metric:   "foo/op",
+		expected: BenchResults{},
+	},
+}
+
+func TestValues(t *testing.T) {
+	values, err := sampleBench.Results.Values("ns/op")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := []float64{55357, 13.3, 20361, 62.7}; !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values\nexpected=%v\nactual=%v", expected, values)
+	}
+
+	if _, err := sampleBench.Results.Values("MB/s"); !errors.Is(err, ErrNotMeasured) {
+		t.Errorf("unexpected error\nexpected=%v\nactual=%v", ErrNotMeasured, err)
+	}
+	if _, err := sampleBench.Results.Values("foo/op"); !errors.Is(err, errUnknownMetric) {
+		t.Errorf("unexpected error\nexpected=%v\nactual=%v", errUnknownMetric, err)
+	}
+}
+
+func TestTopN(t *testing.T) {
+	results := append(BenchResults{}, sampleBench.Results...)
+	slowest, err := results.TopN("ns/op", 2, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := (BenchResults{sampleBench.Results[0], sampleBench.Results[2]}); !reflect.DeepEqual(slowest, expected) {
+		t.Errorf("unexpected slowest results\nexpected:\n%v\nactual:\n%v", expected, slowest)
+	}
+	if !reflect.DeepEqual(results, sampleBench.Results) {
+		t.Errorf("results unexpectedly modified: %v", results)
+	}
+
+	fastest, err := results.TopN("ns/op", 10, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := (BenchResults{sampleBench.Results[1], sampleBench.Results[3], sampleBench.Results[2], sampleBench.Results[0]}); !reflect.DeepEqual(fastest, expected) {
+		t.Errorf("unexpected fastest results\nexpected:\n%v\nactual:\n%v", expected, fastest)
+	}
+
+	notMeasured, err := results.TopN("MB/s", 2, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(notMeasured) != 0 {
+		t.Errorf("unexpected results: %v", notMeasured)
+	}
+
+	if _, err := results.TopN("foo/op", 2, true); !errors.Is(err, errUnknownMetric) {
+		t.Errorf("unexpected error\nexpected=%v\nactual=%v", errUnknownMetric, err)
+	}
+}
+
+func TestMissingMetric(t *testing.T) {
+	for testName, testCase := range missingMetricTests {
+		t.Run(testName, func(t *testing.T) {
+			missing := testCase.results.MissingMetric(testCase.metric)
+			if !reflect.DeepEqual(missing, testCase.expected) {
+				t.Errorf("unexpected results\nexpected:\n%v\nactual:\n%v", testCase.expected, missing)
+			}
+		})
+	}
+}
+
+var setOperationTests = map[string]struct {
+	left                 BenchResults
+	right                BenchResults
+	expectedIntersection BenchResults
+	expectedUnion        BenchResults
+}{
+	"partial_overlap": {
+		left:                 sampleBench.Results[:3],
+		right:                sampleBench.Results[1:],
+		expectedIntersection: BenchResults{sampleBench.Results[1], sampleBench.Results[2]},
+		expectedUnion:        sampleBench.Results,
+	},
+	"disjoint": {
+		left:                 sampleBench.Results[:2],
+		right:                sampleBench.Results[2:],
+		expectedIntersection: BenchResults{},
+		expectedUnion:        sampleBench.Results,
+	},
+	"duplicate_inputs": {
+		left:                 BenchResults{sampleBench.Results[0], sampleBench.Results[0]},
+		right:                BenchResults{sampleBench.Results[0], sampleBench.Results[1]},
+		expectedIntersection: BenchResults{sampleBench.Results[0]},
+		expectedUnion:        BenchResults{sampleBench.Results[0], sampleBench.Results[1]},
+	},
+}
+
+func TestSetOperations(t *testing.T) {
+	for testName, testCase := range setOperationTests {
+		t.Run(testName, func(t *testing.T) {
+			intersection := testCase.left.Intersect(testCase.right)
+			if !reflect.DeepEqual(intersection, testCase.expectedIntersection) {
+				t.Errorf("unexpected intersection\nexpected:\n%v\nactual:\n%v", testCase.expectedIntersection, intersection)
+			}
+
+			union := testCase.left.Union(testCase.right)
+			if !reflect.DeepEqual(union, testCase.expectedUnion) {
+				t.Errorf("unexpected union\nexpected:\n%v\nactual:\n%v", testCase.expectedUnion, union)
+			}
+		})
+	}
+}
+
+var distinctValuesTests = map[string]struct {
+	results          BenchResults
+	varName          string
+	expectedDistinct []interface{}
+	expectedErr      error
+}{
+	"float_var": {
+		results:          sampleBench.Results,
+		varName:          "delta",
+		expectedDistinct: []interface{}{0.001, 1.0},
+	},
+	"int_var": {
+		results:          sampleBench.Results,
+		varName:          "start_x",
+		expectedDistinct: []interface{}{-2, -1},
+	},
+	"string_var": {
+		results:          sampleBench.Results,
+		varName:          "y",
+		expectedDistinct: []interface{}{"2x+3", "sin(x)"},
+	},
+	"bool_var": {
+		results:          sampleBench.Results,
+		varName:          "abs_val",
+		expectedDistinct: []interface{}{true, false},
+	},
+	"missing_var": {
+		results:          sampleBench.Results,
+		varName:          "foo",
+		expectedDistinct: []interface{}{},
+	},
+	"mixed_types": {
+		results: BenchResults{
+			{Inputs: BenchInputs{VarValues: []BenchVarValue{{Name: "foo", Value: 1}}}},
+			{Inputs: BenchInputs{VarValues: []BenchVarValue{{Name: "foo", Value: "bar"}}}},
+		},
+		varName:     "foo",
+		expectedErr: errNonComparable,
+	},
+}
+
+func TestDistinctValues(t *testing.T) {
+	for testName, testCase := range distinctValuesTests {
+		t.Run(testName, func(t *testing.T) {
+			distinct, err := testCase.results.DistinctValues(testCase.varName)
+			if err != nil {
+				if !errors.Is(err, testCase.expectedErr) {
+					t.Errorf("unexpected error\nexpected=%s\nactual=%s", testCase.expectedErr, err)
+				}
+				return
+			}
+			if testCase.expectedErr != nil {
+				t.Fatalf("unexpectedly no error")
+			}
+
+			if !reflect.DeepEqual(distinct, testCase.expectedDistinct) {
+				t.Errorf("unexpected distinct values\nexpected:\n%v\nactual:\n%v", testCase.expectedDistinct, distinct)
+			}
+		})
+	}
+}
+
+var totalAllocsTests = map[string]struct {
+	results                 BenchResults
+	expectedAllocedBytes    uint64
+	expectedAllocedBytesErr error
+	expectedAllocs          uint64
+	expectedAllocsErr       error
+}{
+	"all_measured": {
+		results: BenchResults{
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 10, AllocedBytesPerOp: 32, AllocsPerOp: 2, Measured: parse.AllocedBytesPerOp | parse.AllocsPerOp}}},
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 5, AllocedBytesPerOp: 8, AllocsPerOp: 1, Measured: parse.AllocedBytesPerOp | parse.AllocsPerOp}}},
+		},
+		expectedAllocedBytes: 360,
+		expectedAllocs:       25,
+	},
+	"partially_measured": {
+		results: BenchResults{
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 10, AllocedBytesPerOp: 32, AllocsPerOp: 2, Measured: parse.AllocedBytesPerOp | parse.AllocsPerOp}}},
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 5, NsPerOp: 8, Measured: parse.NsPerOp}}},
+		},
+		expectedAllocedBytes: 320,
+		expectedAllocs:       20,
+	},
+	"none_measured": {
+		results: BenchResults{
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 5, NsPerOp: 8, Measured: parse.NsPerOp}}},
+		},
+		expectedAllocedBytesErr: ErrNotMeasured,
+		expectedAllocsErr:       ErrNotMeasured,
+	},
+	"overflow": {
+		results: BenchResults{
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 10, AllocedBytesPerOp: math.MaxUint64 / 2, AllocsPerOp: math.MaxUint64 / 10, Measured: parse.AllocedBytesPerOp | parse.AllocsPerOp}}},
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 10, AllocsPerOp: math.MaxUint64 / 10, Measured: parse.AllocedBytesPerOp | parse.AllocsPerOp}}},
+		},
+		expectedAllocedBytesErr: errOverflow,
+		expectedAllocsErr:       errOverflow,
+	},
+}
+
+func TestTotalAllocs(t *testing.T) {
+	for testName, testCase := range totalAllocsTests {
+		t.Run(testName, func(t *testing.T) {
+			allocedBytes, err := testCase.results.TotalAllocedBytes()
+			if !errors.Is(err, testCase.expectedAllocedBytesErr) {
+				t.Errorf("unexpected TotalAllocedBytes() error\nexpected=%v\nactual=%v", testCase.expectedAllocedBytesErr, err)
+			}
+			if allocedBytes != testCase.expectedAllocedBytes {
+				t.Errorf("unexpected TotalAllocedBytes() (expected=%d, actual=%d)", testCase.expectedAllocedBytes, allocedBytes)
+			}
+
+			allocs, err := testCase.results.TotalAllocs()
+			if !errors.Is(err, testCase.expectedAllocsErr) {
+				t.Errorf("unexpected TotalAllocs() error\nexpected=%v\nactual=%v", testCase.expectedAllocsErr, err)
+			}
+			if allocs != testCase.expectedAllocs {
+				t.Errorf("unexpected TotalAllocs() (expected=%d, actual=%d)", testCase.expectedAllocs, allocs)
+			}
+		})
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	labels, rows, err := sampleBench.Results.Matrix([]string{"ns/op", "MB/s", "allocs/op"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expectedLabels := []string{
+		"/areaUnder/y=sin(x)/delta=0.001/start_x=-2/end_x=1/abs_val=true-4",
+		"/areaUnder/y=2x+3/delta=1.0/start_x=-1/end_x=2/abs_val=false-4",
+		"/max/y=2x+3/delta=0.001/start_x=-2/end_x=1-4",
+		"/max/y=sin(x)/delta=1.0/start_x=-1/end_x=2-4",
+	}
+	if !reflect.DeepEqual(labels, expectedLabels) {
+		t.Errorf("unexpected labels\nexpected:\n%v\nactual:\n%v", expectedLabels, labels)
+	}
+
+	expectedNsPerOp := []float64{55357, 13.3, 20361, 62.7}
+	if len(rows) != len(expectedNsPerOp) {
+		t.Fatalf("unexpected number of rows (expected=%d, actual=%d)", len(expectedNsPerOp), len(rows))
+	}
+	for i, row := range rows {
+		if row[0] != expectedNsPerOp[i] || !math.IsNaN(row[1]) || row[2] != 0 {
+			t.Errorf("unexpected row %d: %v", i, row)
+		}
+	}
+
+	if _, _, err := sampleBench.Results.Matrix([]string{"foo/op"}); !errors.Is(err, errUnknownMetric) {
+		t.Errorf("unexpected error\nexpected=%s\nactual=%v", errUnknownMetric, err)
+	}
+}
+
 func BenchmarkFilterByInt(b *testing.B) {
 	var (
 		allComps      = []Comparison{Eq, Ne, Lt, Gt, Le, Ge}
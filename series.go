@@ -0,0 +1,199 @@
+package benchparse
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// AxisScale is the suggested scale of a chart axis.
+type AxisScale int
+
+// The available axis scales.
+const (
+	ScaleLinear AxisScale = iota
+	ScaleLog
+)
+
+func (a AxisScale) String() string {
+	switch a {
+	case ScaleLinear:
+		return "linear"
+	case ScaleLog:
+		return "log"
+	default:
+		return fmt.Sprintf("AxisScale(%d)", int(a))
+	}
+}
+
+// SeriesPoint is a single point of a Series.
+type SeriesPoint struct {
+	X float64
+	Y float64
+}
+
+// Series is the value of a metric as a function of a numeric input
+// variable, for use when charting results.
+type Series struct {
+	XVar   string
+	Metric string
+	Points []SeriesPoint // sorted by X
+
+	// XScale is the suggested scale of the x-axis, see
+	// BenchResults.Series for details.
+	XScale AxisScale
+}
+
+var errNonNumericVar = errors.New("variable is not numeric")
+
+// Series returns the value of the named metric for each value of the
+// input variable xVar, with the mean used for values with multiple
+// results. Results without the variable or where the metric was not
+// measured are skipped, and an error is returned if the variable has
+// a non-numeric value.
+//
+// The suggested XScale is ScaleLog if there are at least 3 distinct x
+// values which are all positive and roughly geometrically spaced,
+// meaning the ratio of each sorted value to the previous is at least 2
+// and the largest such ratio is at most twice the smallest (e.g. 1, 10,
+// 100, 1000 or 1, 2, 4, 8). Otherwise it is ScaleLinear.
+func (b BenchResults) Series(xVar, metric string) (Series, error) {
+	observed, err := b.varMetricPoints(xVar, metric)
+	if err != nil {
+		return Series{}, err
+	}
+
+	var (
+		xs  = []float64{}
+		byX = map[float64][]float64{}
+	)
+	for _, p := range observed {
+		if _, ok := byX[p.X]; !ok {
+			xs = append(xs, p.X)
+		}
+		byX[p.X] = append(byX[p.X], p.Y)
+	}
+
+	sort.Float64s(xs)
+	points := make([]SeriesPoint, len(xs))
+	for i, x := range xs {
+		points[i] = SeriesPoint{X: x, Y: mean(byX[x])}
+	}
+	return Series{
+		XVar:   xVar,
+		Metric: metric,
+		Points: points,
+		XScale: suggestedScale(xs),
+	}, nil
+}
+
+// Violation is a pair of adjacent points of a Series where the metric
+// moved against the expected direction.
+type Violation struct {
+	Prev  SeriesPoint
+	Point SeriesPoint
+}
+
+var errUnknownDirection = errors.New("unknown metric direction")
+
+// CheckMonotonic checks that the named metric gets no better as the
+// numeric input variable sizeVar grows, as is expected of e.g. ns/op
+// in a benchmark scaling with input size. direction is whether lower
+// or higher values of the metric are better; if UnknownDirection the
+// direction of the metric (see MetricDirection) is used instead.
+//
+// The results are reduced to a Series, and a Violation is returned for
+// each point where the metric improved on the previous point, which
+// usually signals measurement noise or an algorithmic cliff.
+func (b BenchResults) CheckMonotonic(sizeVar, metric string, direction Direction) ([]Violation, error) {
+	if direction == UnknownDirection {
+		direction = MetricDirection(metric)
+	}
+	if direction != LowerIsBetter && direction != HigherIsBetter {
+		return nil, fmt.Errorf("%w: %s", errUnknownDirection, metric)
+	}
+
+	series, err := b.Series(sizeVar, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []Violation{}
+	for i := 1; i < len(series.Points); i++ {
+		prev, point := series.Points[i-1], series.Points[i]
+		improved := point.Y < prev.Y
+		if direction == HigherIsBetter {
+			improved = point.Y > prev.Y
+		}
+		if improved {
+			violations = append(violations, Violation{Prev: prev, Point: point})
+		}
+	}
+	return violations, nil
+}
+
+// varMetricPoints returns the value of the named numeric input variable
+// and metric of each result, in order. Results without the variable or
+// where the metric was not measured are skipped, and an error is
+// returned if the variable has a non-numeric value.
+func (b BenchResults) varMetricPoints(varName, metric string) ([]SeriesPoint, error) {
+	points := []SeriesPoint{}
+	for _, res := range b {
+		var (
+			x        float64
+			xPresent bool
+		)
+		for _, varVal := range res.Inputs.VarValues {
+			if varVal.Name != varName {
+				continue
+			}
+			v := reflect.ValueOf(varVal.Value)
+			if !isNumeric(v.Kind()) {
+				return nil, fmt.Errorf("%w: %s", errNonNumericVar, varVal)
+			}
+			f, err := getFloat(v, v.Kind())
+			if err != nil {
+				return nil, err
+			}
+			x, xPresent = f, true
+			break
+		}
+		if !xPresent {
+			continue
+		}
+
+		y, err := metricValue(res.Outputs, metric)
+		if err != nil {
+			if errors.Is(err, ErrNotMeasured) {
+				continue
+			}
+			return nil, err
+		}
+		points = append(points, SeriesPoint{X: x, Y: y})
+	}
+	return points, nil
+}
+
+// suggestedScale returns the suggested scale of an axis with the
+// provided sorted, distinct values. See BenchResults.Series for
+// details.
+func suggestedScale(sorted []float64) AxisScale {
+	if len(sorted) < 3 || sorted[0] <= 0 {
+		return ScaleLinear
+	}
+	minRatio, maxRatio := sorted[1]/sorted[0], sorted[1]/sorted[0]
+	for i := 2; i < len(sorted); i++ {
+		ratio := sorted[i] / sorted[i-1]
+		if ratio < minRatio {
+			minRatio = ratio
+		}
+		if ratio > maxRatio {
+			maxRatio = ratio
+		}
+	}
+	if minRatio >= 2 && maxRatio <= 2*minRatio {
+		return ScaleLog
+	}
+	return ScaleLinear
+}
@@ -0,0 +1,209 @@
+package benchparse
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var seriesTests = map[string]struct {
+	results        BenchResults
+	xVar           string
+	metric         string
+	expectedSeries Series
+	expectedErr    error
+}{
+	"geometric_x": {
+		results: sizeResults("ns/op", 1000, 40, 1, 10, 100, 30, 10, 20),
+		xVar:    "size",
+		metric:  "ns/op",
+		expectedSeries: Series{
+			XVar:   "size",
+			Metric: "ns/op",
+			Points: []SeriesPoint{{X: 1, Y: 10}, {X: 10, Y: 20}, {X: 100, Y: 30}, {X: 1000, Y: 40}},
+			XScale: ScaleLog,
+		},
+	},
+	"powers_of_two_x": {
+		results: sizeResults("ns/op", 1, 10, 2, 20, 4, 30, 8, 40),
+		xVar:    "size",
+		metric:  "ns/op",
+		expectedSeries: Series{
+			XVar:   "size",
+			Metric: "ns/op",
+			Points: []SeriesPoint{{X: 1, Y: 10}, {X: 2, Y: 20}, {X: 4, Y: 30}, {X: 8, Y: 40}},
+			XScale: ScaleLog,
+		},
+	},
+	"evenly_spaced_x": {
+		results: sizeResults("ns/op", 10, 10, 20, 20, 30, 30, 40, 40),
+		xVar:    "size",
+		metric:  "ns/op",
+		expectedSeries: Series{
+			XVar:   "size",
+			Metric: "ns/op",
+			Points: []SeriesPoint{{X: 10, Y: 10}, {X: 20, Y: 20}, {X: 30, Y: 30}, {X: 40, Y: 40}},
+			XScale: ScaleLinear,
+		},
+	},
+	"irregular_x": {
+		results: sizeResults("ns/op", 1, 10, 2, 20, 4, 30, 1000, 40),
+		xVar:    "size",
+		metric:  "ns/op",
+		expectedSeries: Series{
+			XVar:   "size",
+			Metric: "ns/op",
+			Points: []SeriesPoint{{X: 1, Y: 10}, {X: 2, Y: 20}, {X: 4, Y: 30}, {X: 1000, Y: 40}},
+			XScale: ScaleLinear,
+		},
+	},
+	"too_few_points": {
+		results: sizeResults("ns/op", 1, 10, 1000, 40),
+		xVar:    "size",
+		metric:  "ns/op",
+		expectedSeries: Series{
+			XVar:   "size",
+			Metric: "ns/op",
+			Points: []SeriesPoint{{X: 1, Y: 10}, {X: 1000, Y: 40}},
+			XScale: ScaleLinear,
+		},
+	},
+	"repeated_x": {
+		results: append(sizeResults("ns/op", 1, 10), sizeResults("ns/op", 1, 20)...),
+		xVar:    "size",
+		metric:  "ns/op",
+		expectedSeries: Series{
+			XVar:   "size",
+			Metric: "ns/op",
+			Points: []SeriesPoint{{X: 1, Y: 15}},
+			XScale: ScaleLinear,
+		},
+	},
+	"float_x": {
+		results: sampleBench.Results,
+		xVar:    "delta",
+		metric:  "ns/op",
+		expectedSeries: Series{
+			XVar:   "delta",
+			Metric: "ns/op",
+			Points: []SeriesPoint{{X: 0.001, Y: (55357 + 20361) / 2.0}, {X: 1, Y: (13.3 + 62.7) / 2.0}},
+			XScale: ScaleLinear,
+		},
+	},
+	"metric_not_measured": {
+		results: sampleBench.Results,
+		xVar:    "delta",
+		metric:  "MB/s",
+		expectedSeries: Series{
+			XVar:   "delta",
+			Metric: "MB/s",
+			Points: []SeriesPoint{},
+			XScale: ScaleLinear,
+		},
+	},
+	"non_numeric_x": {
+		results:     sampleBench.Results,
+		xVar:        "y",
+		metric:      "ns/op",
+		expectedErr: errNonNumericVar,
+	},
+	"unknown_metric": {
+		results:     sampleBench.Results,
+		xVar:        "delta",
+		metric:      "foo/op",
+		expectedErr: errUnknownMetric,
+	},
+}
+
+func TestSeries(t *testing.T) {
+	for testName, testCase := range seriesTests {
+		t.Run(testName, func(t *testing.T) {
+			series, err := testCase.results.Series(testCase.xVar, testCase.metric)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if testCase.expectedErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(series, testCase.expectedSeries) {
+				t.Errorf("unexpected series\nexpected:\n%+v\nactual:\n%+v", testCase.expectedSeries, series)
+			}
+		})
+	}
+}
+
+var checkMonotonicTests = map[string]struct {
+	results            BenchResults
+	sizeVar            string
+	metric             string
+	direction          Direction
+	expectedViolations []Violation
+	expectedErr        error
+}{
+	"monotonic": {
+		results:            sizeResults("ns/op", 1, 10, 2, 20, 4, 20, 8, 80),
+		sizeVar:            "size",
+		metric:             "ns/op",
+		direction:          LowerIsBetter,
+		expectedViolations: []Violation{},
+	},
+	"violations": {
+		results:   sizeResults("ns/op", 1, 10, 2, 30, 4, 20, 8, 80, 16, 70),
+		sizeVar:   "size",
+		metric:    "ns/op",
+		direction: LowerIsBetter,
+		expectedViolations: []Violation{
+			{Prev: SeriesPoint{X: 2, Y: 30}, Point: SeriesPoint{X: 4, Y: 20}},
+			{Prev: SeriesPoint{X: 8, Y: 80}, Point: SeriesPoint{X: 16, Y: 70}},
+		},
+	},
+	"higher_is_better": {
+		results:   sizeResults("ns/op", 1, 10, 2, 30, 4, 20),
+		sizeVar:   "size",
+		metric:    "ns/op",
+		direction: HigherIsBetter,
+		expectedViolations: []Violation{
+			{Prev: SeriesPoint{X: 1, Y: 10}, Point: SeriesPoint{X: 2, Y: 30}},
+		},
+	},
+	"metric_direction": {
+		results:   sizeResults("ns/op", 1, 10, 2, 5),
+		sizeVar:   "size",
+		metric:    "ns/op",
+		direction: UnknownDirection,
+		expectedViolations: []Violation{
+			{Prev: SeriesPoint{X: 1, Y: 10}, Point: SeriesPoint{X: 2, Y: 5}},
+		},
+	},
+	"unknown_direction": {
+		results:     sizeResults("ns/op", 1, 10, 2, 5),
+		sizeVar:     "size",
+		metric:      "N",
+		direction:   UnknownDirection,
+		expectedErr: errUnknownDirection,
+	},
+	"non_numeric_var": {
+		results:     sampleBench.Results,
+		sizeVar:     "y",
+		metric:      "ns/op",
+		direction:   LowerIsBetter,
+		expectedErr: errNonNumericVar,
+	},
+}
+
+func TestCheckMonotonic(t *testing.T) {
+	for testName, testCase := range checkMonotonicTests {
+		t.Run(testName, func(t *testing.T) {
+			violations, err := testCase.results.CheckMonotonic(testCase.sizeVar, testCase.metric, testCase.direction)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if testCase.expectedErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(violations, testCase.expectedViolations) {
+				t.Errorf("unexpected violations\nexpected:\n%+v\nactual:\n%+v", testCase.expectedViolations, violations)
+			}
+		})
+	}
+}
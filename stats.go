@@ -0,0 +1,603 @@
+package benchparse
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// Reducer reduces a set of metric values to a single value.
+type Reducer int
+
+// The available reducers.
+const (
+	ReduceMean Reducer = iota
+	ReduceMedian
+	ReduceMin
+	ReduceMax
+	ReduceSum
+)
+
+var errInvalidReducer = errors.New("invalid reducer")
+
+func (r Reducer) String() string {
+	switch r {
+	case ReduceMean:
+		return "mean"
+	case ReduceMedian:
+		return "median"
+	case ReduceMin:
+		return "min"
+	case ReduceMax:
+		return "max"
+	case ReduceSum:
+		return "sum"
+	default:
+		return fmt.Sprintf("Reducer(%d)", int(r))
+	}
+}
+
+// reduce reduces the non-empty values to a single value.
+func (r Reducer) reduce(values []float64) (float64, error) {
+	switch r {
+	case ReduceMean:
+		return mean(values), nil
+	case ReduceMedian:
+		return median(values), nil
+	case ReduceMin:
+		min := values[0]
+		for _, v := range values {
+			min = math.Min(min, v)
+		}
+		return min, nil
+	case ReduceMax:
+		max := values[0]
+		for _, v := range values {
+			max = math.Max(max, v)
+		}
+		return max, nil
+	case ReduceSum:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", errInvalidReducer, r)
+	}
+}
+
+// Aggregate reduces the named metric of each group's results to a
+// single value, e.g. the mean ns/op of each group. Results where the
+// metric was not measured are skipped, and groups with no measured
+// results are omitted.
+func (g GroupedResults) Aggregate(metric string, reducer Reducer) (map[string]float64, error) {
+	aggregated := make(map[string]float64, len(g))
+	for k, results := range g {
+		values, err := results.measuredValues(metric)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			continue
+		}
+		v, err := reducer.reduce(values)
+		if err != nil {
+			return nil, err
+		}
+		aggregated[k] = v
+	}
+	return aggregated, nil
+}
+
+// Spread returns the ratio of the largest to the smallest value of the
+// named metric within each group, e.g. the slowest to fastest case. A
+// spread far above 1 within a group of what should be equivalent cases
+// may indicate a problem. Results where the metric was not measured
+// are skipped, and groups with no measured results are omitted. The
+// spread is +Inf for a group where the smallest value is zero but the
+// largest isn't, and 1 if all values are zero.
+func (g GroupedResults) Spread(metric string) (map[string]float64, error) {
+	spread := make(map[string]float64, len(g))
+	for k, results := range g {
+		values, err := results.measuredValues(metric)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			continue
+		}
+		min, max := values[0], values[0]
+		for _, v := range values {
+			min, max = math.Min(min, v), math.Max(max, v)
+		}
+		switch {
+		case max == 0:
+			spread[k] = 1
+		case min == 0:
+			spread[k] = math.Inf(1)
+		default:
+			spread[k] = max / min
+		}
+	}
+	return spread, nil
+}
+
+// AggregateOutputs reduces the outputs of each group's results to a
+// single synthetic BenchOutputs, e.g. the mean of every metric. This
+// allows an aggregated group to be used anywhere a BenchOutputs is,
+// such as in a BenchRes rendered with Benchmark.String.
+//
+// Each metric, including custom metrics and the number of iterations,
+// is reduced separately over the results where it was measured, and is
+// not measured in the aggregated outputs if it wasn't measured by any
+// of the group's results. Since the bytes and allocs per iteration and
+// the number of iterations are integers, they are rounded to the
+// nearest integer.
+func (g GroupedResults) AggregateOutputs(reducer Reducer) (map[string]BenchOutputs, error) {
+	aggregated := make(map[string]BenchOutputs, len(g))
+	for k, results := range g {
+		if len(results) == 0 {
+			continue
+		}
+		outputs, err := results.aggregateOutputs(reducer)
+		if err != nil {
+			return nil, err
+		}
+		aggregated[k] = outputs
+	}
+	return aggregated, nil
+}
+
+// MergedOutputs are the outputs of a set of results merged by
+// BenchResults.Merge.
+type MergedOutputs struct {
+	BenchOutputs     // the mean of each metric, see BenchResults.Merge
+	Samples      int // the number of results merged
+}
+
+// GetCustom returns the mean of the custom metric with the provided
+// unit, see CustomOutputs.
+func (m MergedOutputs) GetCustom(unit string) (float64, error) {
+	return customValue(m.BenchOutputs, unit)
+}
+
+// CustomMetrics returns the mean of each custom metric, see
+// CustomOutputs.
+func (m MergedOutputs) CustomMetrics() map[string]float64 {
+	return reportedMetrics(m.BenchOutputs)
+}
+
+// Merge collapses results with the same inputs, such as those from
+// running a benchmark with '-count', into a single result per input.
+// The results are returned in the order their inputs first appear.
+//
+// The outputs of each merged result are a MergedOutputs reporting the
+// mean of each metric over the results where it was measured, with a
+// metric not measured if it wasn't measured by any of the results. As
+// with GroupedResults.AggregateOutputs, integer metrics are rounded.
+func (b BenchResults) Merge() BenchResults {
+	var (
+		keys   = []string{}
+		byKey  = map[string]BenchResults{}
+		merged = make(BenchResults, 0, len(b))
+	)
+	for _, res := range b {
+		k := res.Inputs.key()
+		if _, ok := byKey[k]; !ok {
+			keys = append(keys, k)
+		}
+		byKey[k] = append(byKey[k], res)
+	}
+	for _, k := range keys {
+		results := byKey[k]
+		outputs, _ := results.aggregateOutputs(ReduceMean) // only fails for an invalid reducer
+		res := results[0]
+		res.Outputs = MergedOutputs{BenchOutputs: outputs, Samples: len(results)}
+		merged = append(merged, res)
+	}
+	return merged
+}
+
+// aggregateOutputs reduces the outputs of the non-empty results, see
+// GroupedResults.AggregateOutputs.
+func (b BenchResults) aggregateOutputs(reducer Reducer) (BenchOutputs, error) {
+	var (
+		outputs = parsedBenchOutputs{}
+		custom  = map[string][]float64{}
+		reduced = map[string]float64{}
+	)
+	for _, metric := range append([]string{"N"}, outputMetrics...) {
+		values, err := b.measuredValues(metric)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			continue
+		}
+		v, err := reducer.reduce(values)
+		if err != nil {
+			return nil, err
+		}
+		reduced[metric] = v
+	}
+	for _, res := range b {
+		for unit, v := range reportedMetrics(res.Outputs) {
+			custom[unit] = append(custom[unit], v)
+		}
+	}
+
+	outputs.N = int(math.Round(reduced["N"]))
+	if v, ok := reduced["ns/op"]; ok {
+		outputs.NsPerOp = v
+		outputs.Measured |= parse.NsPerOp
+	}
+	if v, ok := reduced["MB/s"]; ok {
+		outputs.MBPerS = v
+		outputs.Measured |= parse.MBPerS
+	}
+	if v, ok := reduced["B/op"]; ok {
+		outputs.AllocedBytesPerOp = uint64(math.Round(v))
+		outputs.Measured |= parse.AllocedBytesPerOp
+	}
+	if v, ok := reduced["allocs/op"]; ok {
+		outputs.AllocsPerOp = uint64(math.Round(v))
+		outputs.Measured |= parse.AllocsPerOp
+	}
+	for unit, values := range custom {
+		v, err := reducer.reduce(values)
+		if err != nil {
+			return nil, err
+		}
+		if outputs.extra == nil {
+			outputs.extra = map[string]float64{}
+		}
+		outputs.extra[unit] = v
+	}
+	return outputs, nil
+}
+
+// Stats are summary statistics of a metric across a set of results.
+type Stats struct {
+	Count  int // the number of results where the metric was measured
+	Min    float64
+	Max    float64
+	Mean   float64
+	Median float64
+	StdDev float64 // the sample standard deviation
+}
+
+var errNoMeasurements = errors.New("metric not measured for any result")
+
+// Stats returns summary statistics of the named metric across the
+// results, for example to summarize the results of running a benchmark
+// with '-count'. Results where the metric was not measured are skipped,
+// and an error is returned if it was not measured for any result.
+func (b BenchResults) Stats(metric string) (Stats, error) {
+	values, err := b.measuredValues(metric)
+	if err != nil {
+		return Stats{}, err
+	}
+	if len(values) == 0 {
+		return Stats{}, fmt.Errorf("%w: %s", errNoMeasurements, metric)
+	}
+
+	stats := Stats{
+		Count:  len(values),
+		Mean:   mean(values),
+		Median: median(values),
+		StdDev: stdDev(values),
+	}
+	stats.Min, _ = ReduceMin.reduce(values)
+	stats.Max, _ = ReduceMax.reduce(values)
+	return stats, nil
+}
+
+var errInvalidPercentile = errors.New("percentile must be between 0 and 100")
+
+// Percentile returns the pth percentile (e.g. 90 for p90) of the named
+// metric across the results, for example to report the tail latency of
+// the samples from running a benchmark with '-count'. Percentiles which
+// fall between two values are linearly interpolated, so the 50th
+// percentile is the median.
+//
+// Results where the metric was not measured are skipped, and an error
+// is returned if it was not measured for any result or if p is outside
+// of [0, 100].
+func (b BenchResults) Percentile(metric string, p float64) (float64, error) {
+	if p < 0 || p > 100 || math.IsNaN(p) {
+		return 0, fmt.Errorf("%w: %v", errInvalidPercentile, p)
+	}
+	values, err := b.measuredValues(metric)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, fmt.Errorf("%w: %s", errNoMeasurements, metric)
+	}
+
+	sort.Float64s(values)
+	var (
+		rank   = p / 100 * float64(len(values)-1)
+		lower  = int(math.Floor(rank))
+		upper  = int(math.Ceil(rank))
+		weight = rank - float64(lower)
+	)
+	return values[lower] + weight*(values[upper]-values[lower]), nil
+}
+
+var errNoRepeatedSamples = errors.New("no inputs with repeated samples")
+
+// IsNoisy reports whether the results for any input, such as those from
+// running a benchmark with '-count', vary too much to be trusted. This
+// is the case if the coefficient of variation (the standard deviation
+// divided by the mean) of the named metric across the results with the
+// same inputs exceeds cvThreshold (e.g. 0.05 for 5%).
+//
+// Results where the metric was not measured are skipped, and an error
+// is returned if no input has multiple measured results.
+func (b BenchResults) IsNoisy(metric string, cvThreshold float64) (bool, error) {
+	byInputs, err := b.valuesByInputs(metric)
+	if err != nil {
+		return false, err
+	}
+
+	repeated := false
+	for _, in := range byInputs {
+		if len(in.values) < 2 {
+			continue
+		}
+		repeated = true
+
+		m := mean(in.values)
+		if m == 0 {
+			continue
+		}
+		if stdDev(in.values)/math.Abs(m) > cvThreshold {
+			return true, nil
+		}
+	}
+	if !repeated {
+		return false, errNoRepeatedSamples
+	}
+	return false, nil
+}
+
+// MarginalMeans returns, for each input variable, the mean of the named
+// metric at each of the variable's values, averaged over all other inputs.
+// The returned map is keyed by variable name and then by value. Results
+// where the metric was not measured are skipped.
+//
+// Values are grouped by their type and formatted value, so e.g. results
+// with 'delta=NaN' are averaged together even though NaN != NaN.
+func (b Benchmark) MarginalMeans(metric string) (map[string]map[interface{}]float64, error) {
+	type acc struct {
+		value interface{}
+		sum   float64
+		count int
+	}
+	accs := map[string]map[string]*acc{} // keyed by valueKey
+	for _, res := range b.Results {
+		v, err := metricValue(res.Outputs, metric)
+		if err != nil {
+			if errors.Is(err, ErrNotMeasured) {
+				continue
+			}
+			return nil, err
+		}
+		for _, varVal := range res.Inputs.VarValues {
+			byValue, ok := accs[varVal.Name]
+			if !ok {
+				byValue = map[string]*acc{}
+				accs[varVal.Name] = byValue
+			}
+			k := valueKey(varVal.Value)
+			a, ok := byValue[k]
+			if !ok {
+				a = &acc{value: varVal.Value}
+				byValue[k] = a
+			}
+			a.sum += v
+			a.count++
+		}
+	}
+
+	means := make(map[string]map[interface{}]float64, len(accs))
+	for name, byValue := range accs {
+		means[name] = make(map[interface{}]float64, len(byValue))
+		for _, a := range byValue {
+			means[name][a.value] = a.sum / float64(a.count)
+		}
+	}
+	return means, nil
+}
+
+var errInsufficientData = errors.New("insufficient data")
+
+// Correlation fits a simple linear regression of the named metric
+// against the named numeric input variable across the results,
+// returning the coefficient of determination (R²) and the slope of the
+// fitted line. For example the slope of "ns/op" against a size variable
+// is the cost of each additional unit of size, while an R² close to 1
+// indicates that the metric scales linearly with the size.
+//
+// Results without the variable or where the metric was not measured
+// are skipped. An error is returned if the variable has a non-numeric
+// value, or if the remaining results don't have at least two distinct
+// values of the variable. If the metric has the same value for every
+// result the fit is exact, so R² is 1.
+func (b BenchResults) Correlation(varName, metric string) (r2 float64, slope float64, err error) {
+	points, err := b.varMetricPoints(varName, metric)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(points) == 0 {
+		return 0, 0, fmt.Errorf("%w: no results with %s measured and %s set", errInsufficientData, metric, varName)
+	}
+	xs, ys := make([]float64, len(points)), make([]float64, len(points))
+	for i, p := range points {
+		xs[i], ys[i] = p.X, p.Y
+	}
+
+	var (
+		xMean, yMean  = mean(xs), mean(ys)
+		sxx, sxy, syy float64
+	)
+	for i := range points {
+		dx, dy := xs[i]-xMean, ys[i]-yMean
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return 0, 0, fmt.Errorf("%w: less than 2 distinct values of %s", errInsufficientData, varName)
+	}
+	slope = sxy / sxx
+	if syy == 0 {
+		return 1, slope, nil
+	}
+	return sxy * sxy / (sxx * syy), slope, nil
+}
+
+// EfficiencyPoint is the parallel speedup of a case at a specific
+// GOMAXPROCS relative to the same case with GOMAXPROCS=1.
+type EfficiencyPoint struct {
+	Procs      int
+	Value      float64 // the mean value of the metric
+	Speedup    float64
+	Efficiency float64 // the speedup divided by Procs
+}
+
+// ParallelEfficiency computes the parallel speedup and efficiency of
+// each case benchmarked with multiple GOMAXPROCS values, e.g. with
+// '-cpu=1,2,4,8'. Results are grouped by their inputs other than
+// GOMAXPROCS, keyed by the string representation of those inputs with
+// GOMAXPROCS=1, and the points of each group are sorted by Procs.
+//
+// The speedup is relative to the GOMAXPROCS=1 result of the group, with
+// the mean used for inputs with multiple results. It accounts for the
+// direction of the metric (see MetricDirection), so for "ns/op" a
+// speedup of 2 means the case took half as long while for "MB/s" it
+// means the throughput doubled. Groups without a GOMAXPROCS=1 result
+// have no baseline so are omitted, as are results where the metric was
+// not measured.
+func (b Benchmark) ParallelEfficiency(metric string) (map[string][]EfficiencyPoint, error) {
+	means, err := meanByInputs(b.Results, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	byGroup := map[string][]inputMean{}
+	for _, m := range means {
+		inputs := m.inputs
+		inputs.MaxProcs = 1
+		k := inputs.String()
+		byGroup[k] = append(byGroup[k], m)
+	}
+
+	efficiency := map[string][]EfficiencyPoint{}
+	for k, group := range byGroup {
+		var (
+			baseline    float64
+			hasBaseline bool
+		)
+		for _, m := range group {
+			if m.inputs.MaxProcs == 1 {
+				baseline, hasBaseline = m.mean, true
+			}
+		}
+		if !hasBaseline {
+			continue
+		}
+
+		points := make([]EfficiencyPoint, len(group))
+		for i, m := range group {
+			speedup := baseline / m.mean
+			if MetricDirection(metric) == HigherIsBetter {
+				speedup = m.mean / baseline
+			}
+			points[i] = EfficiencyPoint{
+				Procs:      m.inputs.MaxProcs,
+				Value:      m.mean,
+				Speedup:    speedup,
+				Efficiency: speedup / float64(m.inputs.MaxProcs),
+			}
+		}
+		sort.Slice(points, func(i, j int) bool {
+			return points[i].Procs < points[j].Procs
+		})
+		efficiency[k] = points
+	}
+	return efficiency, nil
+}
+
+type inputValues struct {
+	inputs BenchInputs
+	values []float64
+}
+
+// valuesByInputs returns the measured values of the metric for each
+// distinct input, in the order the inputs first appear.
+func (b BenchResults) valuesByInputs(metric string) ([]inputValues, error) {
+	var (
+		byInputs = []inputValues{}
+		index    = map[string]int{}
+	)
+	for _, res := range b {
+		v, err := metricValue(res.Outputs, metric)
+		if err != nil {
+			if errors.Is(err, ErrNotMeasured) {
+				continue
+			}
+			return nil, err
+		}
+		k := res.Inputs.key()
+		i, ok := index[k]
+		if !ok {
+			i = len(byInputs)
+			index[k] = i
+			byInputs = append(byInputs, inputValues{inputs: res.Inputs})
+		}
+		byInputs[i].values = append(byInputs[i].values, v)
+	}
+	return byInputs, nil
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// stdDev returns the sample standard deviation of the values.
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var (
+		m  = mean(values)
+		ss float64
+	)
+	for _, v := range values {
+		ss += (v - m) * (v - m)
+	}
+	return math.Sqrt(ss / float64(len(values)-1))
+}
@@ -0,0 +1,587 @@
+package benchparse
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+// sinCase and lineCase are single cases of sampleBench, for building
+// repeated samples of them with withMetric.
+var (
+	sinCase  = Benchmark{Name: sampleBench.Name, Results: sampleBench.Results[:1]}
+	lineCase = Benchmark{Name: sampleBench.Name, Results: sampleBench.Results[1:2]}
+)
+
+var isNoisyTests = map[string]struct {
+	results       BenchResults
+	metric        string
+	cvThreshold   float64
+	expectedNoisy bool
+	expectedErr   error
+}{
+	"stable": {
+		results:     append(withMetric(sinCase, "ns/op", 100, 101, 99).Results, withMetric(lineCase, "ns/op", 50, 50).Results...),
+		metric:      "ns/op",
+		cvThreshold: 0.05,
+	},
+	"noisy": {
+		results:       append(withMetric(sinCase, "ns/op", 100, 101, 99).Results, withMetric(lineCase, "ns/op", 50, 100).Results...),
+		metric:        "ns/op",
+		cvThreshold:   0.05,
+		expectedNoisy: true,
+	},
+	"no_repeated_samples": {
+		results:     sampleBench.Results,
+		metric:      "ns/op",
+		cvThreshold: 0.05,
+		expectedErr: errNoRepeatedSamples,
+	},
+	"unknown_metric": {
+		results:     sampleBench.Results,
+		metric:      "foo/op",
+		expectedErr: errUnknownMetric,
+	},
+}
+
+func TestIsNoisy(t *testing.T) {
+	for testName, testCase := range isNoisyTests {
+		t.Run(testName, func(t *testing.T) {
+			noisy, err := testCase.results.IsNoisy(testCase.metric, testCase.cvThreshold)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Errorf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if noisy != testCase.expectedNoisy {
+				t.Errorf("unexpected noisy (expected=%t, actual=%t)", testCase.expectedNoisy, noisy)
+			}
+		})
+	}
+}
+
+var statsTests = map[string]struct {
+	results       BenchResults
+	metric        string
+	expectedStats Stats
+	expectedErr   error
+}{
+	"even_count": {
+		results: withMetric(sinCase, "ns/op", 40, 10, 30, 20).Results,
+		metric:  "ns/op",
+		expectedStats: Stats{
+			Count:  4,
+			Min:    10,
+			Max:    40,
+			Mean:   25,
+			Median: 25,
+			StdDev: math.Sqrt(500.0 / 3),
+		},
+	},
+	"single_result": {
+		results:       withMetric(sinCase, "ns/op", 10).Results,
+		metric:        "ns/op",
+		expectedStats: Stats{Count: 1, Min: 10, Max: 10, Mean: 10, Median: 10},
+	},
+	"skips_not_measured": {
+		results: append(withMetric(sinCase, "ns/op", 10, 30, 20).Results, BenchRes{
+			Inputs:  sampleBench.Results[0].Inputs,
+			Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, AllocsPerOp: 1, Measured: parse.AllocsPerOp}},
+		}),
+		metric:        "ns/op",
+		expectedStats: Stats{Count: 3, Min: 10, Max: 30, Mean: 20, Median: 20, StdDev: 10},
+	},
+	"not_measured": {
+		results:     withMetric(sinCase, "ns/op", 10, 20).Results,
+		metric:      "MB/s",
+		expectedErr: errNoMeasurements,
+	},
+	"empty": {
+		results:     BenchResults{},
+		metric:      "ns/op",
+		expectedErr: errNoMeasurements,
+	},
+	"unknown_metric": {
+		results:     withMetric(sinCase, "ns/op", 10, 20).Results,
+		metric:      "foo/op",
+		expectedErr: errUnknownMetric,
+	},
+}
+
+func TestStats(t *testing.T) {
+	for testName, testCase := range statsTests {
+		t.Run(testName, func(t *testing.T) {
+			stats, err := testCase.results.Stats(testCase.metric)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if !reflect.DeepEqual(stats, testCase.expectedStats) {
+				t.Errorf("unexpected stats\nexpected:\n%+v\nactual:\n%+v", testCase.expectedStats, stats)
+			}
+		})
+	}
+}
+
+var aggregateTests = map[string]struct {
+	grouped            GroupedResults
+	metric             string
+	reducer            Reducer
+	expectedAggregated map[string]float64
+	expectedErr        error
+}{
+	"mean": {
+		grouped:            sampleBench.Results.Group([]string{"y"}),
+		metric:             "ns/op",
+		reducer:            ReduceMean,
+		expectedAggregated: map[string]float64{"y=sin(x)": 27709.85, "y=2x+3": 10187.15},
+	},
+	"median": {
+		grouped: GroupedResults{
+			"odd":  withMetric(sinCase, "ns/op", 3, 1, 2).Results,
+			"even": withMetric(sinCase, "ns/op", 4, 1, 3, 2).Results,
+		},
+		metric:             "ns/op",
+		reducer:            ReduceMedian,
+		expectedAggregated: map[string]float64{"odd": 2, "even": 2.5},
+	},
+	"min": {
+		grouped:            sampleBench.Results.Group([]string{"y"}),
+		metric:             "ns/op",
+		reducer:            ReduceMin,
+		expectedAggregated: map[string]float64{"y=sin(x)": 62.7, "y=2x+3": 13.3},
+	},
+	"max": {
+		grouped:            sampleBench.Results.Group([]string{"y"}),
+		metric:             "ns/op",
+		reducer:            ReduceMax,
+		expectedAggregated: map[string]float64{"y=sin(x)": 55357, "y=2x+3": 20361},
+	},
+	"sum": {
+		grouped:            sampleBench.Results.Group([]string{"y"}),
+		metric:             "N",
+		reducer:            ReduceSum,
+		expectedAggregated: map[string]float64{"y=sin(x)": 16402939, "y=2x+3": 88392207},
+	},
+	"unmeasured_groups_omitted": {
+		grouped:            sampleBench.Results.Group([]string{"y"}),
+		metric:             "MB/s",
+		reducer:            ReduceMean,
+		expectedAggregated: map[string]float64{},
+	},
+	"invalid_reducer": {
+		grouped:     sampleBench.Results.Group([]string{"y"}),
+		metric:      "ns/op",
+		reducer:     Reducer(-1),
+		expectedErr: errInvalidReducer,
+	},
+}
+
+var percentileTests = map[string]struct {
+	results     BenchResults
+	metric      string
+	p           float64
+	expectedV   float64
+	expectedErr error
+}{
+	"p50_even_count": {
+		results:   withMetric(sinCase, "ns/op", 40, 10, 30, 20).Results,
+		metric:    "ns/op",
+		p:         50,
+		expectedV: 25,
+	},
+	"p90_interpolated": {
+		results:   withMetric(sinCase, "ns/op", 50, 10, 40, 20, 30).Results,
+		metric:    "ns/op",
+		p:         90,
+		expectedV: 46,
+	},
+	"p0": {
+		results:   withMetric(sinCase, "ns/op", 40, 10, 30, 20).Results,
+		metric:    "ns/op",
+		p:         0,
+		expectedV: 10,
+	},
+	"p100": {
+		results:   withMetric(sinCase, "ns/op", 40, 10, 30, 20).Results,
+		metric:    "ns/op",
+		p:         100,
+		expectedV: 40,
+	},
+	"single_result": {
+		results:   withMetric(sinCase, "ns/op", 10).Results,
+		metric:    "ns/op",
+		p:         99,
+		expectedV: 10,
+	},
+	"skips_not_measured": {
+		results: append(withMetric(sinCase, "ns/op", 10, 30).Results, BenchRes{
+			Inputs:  sampleBench.Results[0].Inputs,
+			Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, AllocsPerOp: 1, Measured: parse.AllocsPerOp}},
+		}),
+		metric:    "ns/op",
+		p:         50,
+		expectedV: 20,
+	},
+	"not_measured": {
+		results:     withMetric(sinCase, "ns/op", 10, 20).Results,
+		metric:      "MB/s",
+		p:           50,
+		expectedErr: errNoMeasurements,
+	},
+	"negative": {
+		results:     withMetric(sinCase, "ns/op", 10, 20).Results,
+		metric:      "ns/op",
+		p:           -1,
+		expectedErr: errInvalidPercentile,
+	},
+	"above_100": {
+		results:     withMetric(sinCase, "ns/op", 10, 20).Results,
+		metric:      "ns/op",
+		p:           101,
+		expectedErr: errInvalidPercentile,
+	},
+}
+
+func TestPercentile(t *testing.T) {
+	for testName, testCase := range percentileTests {
+		t.Run(testName, func(t *testing.T) {
+			v, err := testCase.results.Percentile(testCase.metric, testCase.p)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if math.Abs(v-testCase.expectedV) > 1e-9 {
+				t.Errorf("unexpected percentile (expected=%v, actual=%v)", testCase.expectedV, v)
+			}
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	for testName, testCase := range aggregateTests {
+		t.Run(testName, func(t *testing.T) {
+			aggregated, err := testCase.grouped.Aggregate(testCase.metric, testCase.reducer)
+			if err != nil {
+				if !errors.Is(err, testCase.expectedErr) {
+					t.Errorf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+				}
+				return
+			}
+			if testCase.expectedErr != nil {
+				t.Fatalf("unexpectedly no error")
+			}
+
+			if !reflect.DeepEqual(aggregated, testCase.expectedAggregated) {
+				t.Errorf("unexpected aggregated values\nexpected:\n%v\nactual:\n%v", testCase.expectedAggregated, aggregated)
+			}
+		})
+	}
+}
+
+var spreadTests = map[string]struct {
+	grouped        GroupedResults
+	metric         string
+	expectedSpread map[string]float64
+	expectedErr    error
+}{
+	"by_func": {
+		grouped: GroupedResults{
+			"a": withMetric(sinCase, "ns/op", 10, 20, 40).Results,
+			"b": withMetric(lineCase, "ns/op", 5).Results,
+		},
+		metric:         "ns/op",
+		expectedSpread: map[string]float64{"a": 4, "b": 1},
+	},
+	"zero_values": {
+		grouped: GroupedResults{
+			"some_zero": withMetric(sinCase, "ns/op", 0, 2).Results,
+			"all_zero":  withMetric(lineCase, "ns/op", 0, 0).Results,
+		},
+		metric:         "ns/op",
+		expectedSpread: map[string]float64{"some_zero": math.Inf(1), "all_zero": 1},
+	},
+	"unmeasured_groups_omitted": {
+		grouped:        sampleBench.Results.Group([]string{"y"}),
+		metric:         "MB/s",
+		expectedSpread: map[string]float64{},
+	},
+	"unknown_metric": {
+		grouped:     sampleBench.Results.Group([]string{"y"}),
+		metric:      "foo/op",
+		expectedErr: errUnknownMetric,
+	},
+}
+
+func TestSpread(t *testing.T) {
+	for testName, testCase := range spreadTests {
+		t.Run(testName, func(t *testing.T) {
+			spread, err := testCase.grouped.Spread(testCase.metric)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if testCase.expectedErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(spread, testCase.expectedSpread) {
+				t.Errorf("unexpected spread\nexpected:\n%v\nactual:\n%v", testCase.expectedSpread, spread)
+			}
+		})
+	}
+}
+
+func TestAggregateOutputs(t *testing.T) {
+	grouped := GroupedResults{
+		"benchmem": sampleBench.Results,
+		"mixed": BenchResults{
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 100, NsPerOp: 10, MBPerS: 4, Measured: parse.NsPerOp | parse.MBPerS}, extra: map[string]float64{"hits/op": 3}}},
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 201, NsPerOp: 20, AllocedBytesPerOp: 5, AllocsPerOp: 1, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}}},
+			{Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 300, NsPerOp: 30, AllocedBytesPerOp: 6, AllocsPerOp: 2, Measured: parse.NsPerOp | parse.AllocedBytesPerOp | parse.AllocsPerOp}}},
+		},
+	}
+	aggregated, err := grouped.AggregateOutputs(ReduceMean)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expectedStrings := map[string]string{
+		"benchmem": "26198787 18948.50 ns/op 0 B/op 0 allocs/op",
+		"mixed":    "200 20.00 ns/op 4.00 MB/s 6 B/op 2 allocs/op 3 hits/op",
+	}
+	if len(aggregated) != len(expectedStrings) {
+		t.Fatalf("unexpected number of groups (expected=%d, actual=%d)", len(expectedStrings), len(aggregated))
+	}
+	for k, expected := range expectedStrings {
+		if s := benchOutputsString(aggregated[k]); s != expected {
+			t.Errorf("unexpected outputs for group %s (expected=%q, actual=%q)", k, expected, s)
+		}
+	}
+
+	if _, err := grouped.AggregateOutputs(Reducer(-1)); !errors.Is(err, errInvalidReducer) {
+		t.Errorf("unexpected error (expected=%v, actual=%v)", errInvalidReducer, err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	results := BenchResults{}
+	results = append(results, withMetric(sinCase, "ns/op", 10).Results...)
+	results = append(results, withMetric(lineCase, "ns/op", 5).Results...)
+	results = append(results, withMetric(sinCase, "ns/op", 20, 30).Results...)
+
+	merged := results.Merge()
+	if len(merged) != 2 {
+		t.Fatalf("unexpected number of merged results (expected=2, actual=%d)", len(merged))
+	}
+
+	expected := []struct {
+		inputs  BenchInputs
+		nsPerOp float64
+		samples int
+	}{
+		{inputs: sampleBench.Results[0].Inputs, nsPerOp: 20, samples: 3},
+		{inputs: sampleBench.Results[1].Inputs, nsPerOp: 5, samples: 1},
+	}
+	for i, res := range merged {
+		if !reflect.DeepEqual(res.Inputs, expected[i].inputs) {
+			t.Errorf("unexpected inputs for result %d\nexpected:\n%v\nactual:\n%v", i, expected[i].inputs, res.Inputs)
+		}
+		outputs, ok := res.Outputs.(MergedOutputs)
+		if !ok {
+			t.Fatalf("unexpected outputs type for result %d: %T", i, res.Outputs)
+		}
+		if outputs.Samples != expected[i].samples {
+			t.Errorf("unexpected samples for result %d (expected=%d, actual=%d)", i, expected[i].samples, outputs.Samples)
+		}
+		if nsPerOp, err := outputs.GetNsPerOp(); err != nil || nsPerOp != expected[i].nsPerOp {
+			t.Errorf("unexpected ns/op for result %d (expected=%v, actual=%v, err=%v)", i, expected[i].nsPerOp, nsPerOp, err)
+		}
+		if _, err := outputs.GetAllocsPerOp(); !errors.Is(err, ErrNotMeasured) {
+			t.Errorf("unexpected allocs/op error for result %d (expected=%v, actual=%v)", i, ErrNotMeasured, err)
+		}
+	}
+
+	custom := BenchResults{
+		testRes(sampleBench.Results[0].Inputs, "hits/op", 1),
+		testRes(sampleBench.Results[0].Inputs, "hits/op", 3),
+	}.Merge()
+	outputs, ok := custom[0].Outputs.(CustomOutputs)
+	if !ok {
+		t.Fatalf("merged outputs do not implement CustomOutputs: %T", custom[0].Outputs)
+	}
+	if hits, err := outputs.GetCustom("hits/op"); err != nil || hits != 2 {
+		t.Errorf("unexpected hits/op (expected=2, actual=%v, err=%v)", hits, err)
+	}
+}
+
+func TestMarginalMeans(t *testing.T) {
+	means, err := sampleBench.MarginalMeans("ns/op")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]map[interface{}]float64{
+		"y":       {"sin(x)": 27709.85, "2x+3": 10187.15},
+		"delta":   {0.001: 37859, 1.0: 38},
+		"start_x": {-2: 37859, -1: 38},
+		"end_x":   {1: 37859, 2: 38},
+		"abs_val": {true: 55357, false: 13.3},
+	}
+	if !reflect.DeepEqual(means, expected) {
+		t.Errorf("unexpected marginal means\nexpected:\n%v\nactual:\n%v", expected, means)
+	}
+
+	nan := withMetric(Benchmark{Name: "BenchmarkFoo", Results: BenchResults{
+		{Inputs: BenchInputs{VarValues: []BenchVarValue{{Name: "delta", Value: math.NaN()}}}},
+	}}, "ns/op", 10, 20)
+	nanMeans, err := nan.MarginalMeans("ns/op")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(nanMeans["delta"]) != 1 {
+		t.Errorf("unexpected number of NaN values (expected=1, actual=%d): %v", len(nanMeans["delta"]), nanMeans)
+	}
+	for _, m := range nanMeans["delta"] {
+		if m != 15 {
+			t.Errorf("unexpected mean of NaN value (expected=15, actual=%g)", m)
+		}
+	}
+
+	if _, err := sampleBench.MarginalMeans("foo/op"); !errors.Is(err, errUnknownMetric) {
+		t.Errorf("unexpected error\nexpected=%s\nactual=%v", errUnknownMetric, err)
+	}
+}
+
+var parallelEfficiencyTests = map[string]struct {
+	bench              Benchmark
+	metric             string
+	expectedEfficiency map[string][]EfficiencyPoint
+	expectedErr        error
+}{
+	"ns_per_op": {
+		bench: Benchmark{Name: "BenchmarkMath", Results: BenchResults{
+			testRes(BenchInputs{Subs: sampleBench.Results[0].Inputs.Subs, VarValues: sampleBench.Results[0].Inputs.VarValues, MaxProcs: 4}, "ns/op", 40),
+			testRes(BenchInputs{Subs: sampleBench.Results[0].Inputs.Subs, VarValues: sampleBench.Results[0].Inputs.VarValues, MaxProcs: 1}, "ns/op", 100),
+			testRes(BenchInputs{Subs: sampleBench.Results[0].Inputs.Subs, VarValues: sampleBench.Results[0].Inputs.VarValues, MaxProcs: 2}, "ns/op", 50),
+			testRes(BenchInputs{Subs: sampleBench.Results[1].Inputs.Subs, VarValues: sampleBench.Results[1].Inputs.VarValues, MaxProcs: 1}, "ns/op", 10),
+			testRes(BenchInputs{Subs: sampleBench.Results[1].Inputs.Subs, VarValues: sampleBench.Results[1].Inputs.VarValues, MaxProcs: 2}, "ns/op", 10),
+			// no baseline
+			testRes(BenchInputs{Subs: sampleBench.Results[2].Inputs.Subs, VarValues: sampleBench.Results[2].Inputs.VarValues, MaxProcs: 2}, "ns/op", 10),
+			testRes(BenchInputs{Subs: sampleBench.Results[2].Inputs.Subs, VarValues: sampleBench.Results[2].Inputs.VarValues, MaxProcs: 4}, "ns/op", 5),
+		}},
+		metric: "ns/op",
+		expectedEfficiency: map[string][]EfficiencyPoint{
+			"/areaUnder/y=sin(x)/delta=0.001/start_x=-2/end_x=1/abs_val=true": {
+				{Procs: 1, Value: 100, Speedup: 1, Efficiency: 1},
+				{Procs: 2, Value: 50, Speedup: 2, Efficiency: 1},
+				{Procs: 4, Value: 40, Speedup: 2.5, Efficiency: 0.625},
+			},
+			"/areaUnder/y=2x+3/delta=1.0/start_x=-1/end_x=2/abs_val=false": {
+				{Procs: 1, Value: 10, Speedup: 1, Efficiency: 1},
+				{Procs: 2, Value: 10, Speedup: 1, Efficiency: 0.5},
+			},
+		},
+	},
+	"mb_per_s": {
+		bench: Benchmark{Name: "BenchmarkMath", Results: BenchResults{
+			testRes(BenchInputs{Subs: sampleBench.Results[0].Inputs.Subs, VarValues: sampleBench.Results[0].Inputs.VarValues, MaxProcs: 1}, "MB/s", 10),
+			testRes(BenchInputs{Subs: sampleBench.Results[0].Inputs.Subs, VarValues: sampleBench.Results[0].Inputs.VarValues, MaxProcs: 2}, "MB/s", 20),
+		}},
+		metric: "MB/s",
+		expectedEfficiency: map[string][]EfficiencyPoint{
+			"/areaUnder/y=sin(x)/delta=0.001/start_x=-2/end_x=1/abs_val=true": {
+				{Procs: 1, Value: 10, Speedup: 1, Efficiency: 1},
+				{Procs: 2, Value: 20, Speedup: 2, Efficiency: 1},
+			},
+		},
+	},
+	"unknown_metric": {
+		bench:       sampleBench,
+		metric:      "foo/op",
+		expectedErr: errUnknownMetric,
+	},
+}
+
+func TestParallelEfficiency(t *testing.T) {
+	for testName, testCase := range parallelEfficiencyTests {
+		t.Run(testName, func(t *testing.T) {
+			efficiency, err := testCase.bench.ParallelEfficiency(testCase.metric)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if testCase.expectedErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(efficiency, testCase.expectedEfficiency) {
+				t.Errorf("unexpected efficiency\nexpected:\n%+v\nactual:\n%+v", testCase.expectedEfficiency, efficiency)
+			}
+		})
+	}
+}
+
+var correlationTests = map[string]struct {
+	results       BenchResults
+	varName       string
+	metric        string
+	expectedR2    float64
+	expectedSlope float64
+	expectedErr   error
+}{
+	"linear": {
+		results:       sizeResults("ns/op", 1, 12, 2, 22, 4, 42, 8, 82),
+		varName:       "size",
+		metric:        "ns/op",
+		expectedR2:    1,
+		expectedSlope: 10,
+	},
+	"noisy": {
+		results:       sizeResults("ns/op", 1, 3, 2, 3, 3, 7, 4, 7),
+		varName:       "size",
+		metric:        "ns/op",
+		expectedR2:    0.8,
+		expectedSlope: 1.6,
+	},
+	"constant_metric": {
+		results:       sizeResults("ns/op", 1, 5, 2, 5),
+		varName:       "size",
+		metric:        "ns/op",
+		expectedR2:    1,
+		expectedSlope: 0,
+	},
+	"single_value": {
+		results:     sizeResults("ns/op", 1, 5, 1, 6),
+		varName:     "size",
+		metric:      "ns/op",
+		expectedErr: errInsufficientData,
+	},
+	"no_results": {
+		results:     sampleBench.Results,
+		varName:     "size",
+		metric:      "ns/op",
+		expectedErr: errInsufficientData,
+	},
+	"non_numeric": {
+		results:     sampleBench.Results,
+		varName:     "y",
+		metric:      "ns/op",
+		expectedErr: errNonNumericVar,
+	},
+}
+
+func TestCorrelation(t *testing.T) {
+	for testName, testCase := range correlationTests {
+		t.Run(testName, func(t *testing.T) {
+			r2, slope, err := testCase.results.Correlation(testCase.varName, testCase.metric)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if testCase.expectedErr != nil {
+				return
+			}
+			if math.Abs(r2-testCase.expectedR2) > 1e-9 {
+				t.Errorf("unexpected r2 (expected=%v, actual=%v)", testCase.expectedR2, r2)
+			}
+			if math.Abs(slope-testCase.expectedSlope) > 1e-9 {
+				t.Errorf("unexpected slope (expected=%v, actual=%v)", testCase.expectedSlope, slope)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package benchparse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Parser incrementally parses testing.B output, returning each result
+// as it is read rather than waiting for the end of the input.
+//
+// Unlike ParseBenchmarks, reaching the end of the input is not treated
+// as final: once the underlying reader returns io.EOF, Next may be
+// called again to parse any data written since. This allows parsing a
+// file which is still being written to (see FollowFile). Since a line
+// may be only partially written, a trailing line without a newline is
+// not parsed until it is terminated.
+type Parser struct {
+	r       *bufio.Reader
+	cfg     parseConfig
+	dups    duplicateChecker
+	samples sampleCounter
+	lineNum int
+	partial []byte // the line read so far
+}
+
+// NewParser returns a Parser reading testing.B output from r.
+func NewParser(r io.Reader, opts ...ParseOption) *Parser {
+	return &Parser{
+		r:       bufio.NewReader(r),
+		cfg:     newParseConfig(opts),
+		dups:    newDuplicateChecker(),
+		samples: sampleCounter{},
+	}
+}
+
+// Next returns the next result, along with the name of the benchmark
+// it belongs to. Lines which are not benchmark results are skipped.
+// io.EOF is returned if there are no complete lines left to parse.
+// Errors parsing a line include its line number.
+func (p *Parser) Next() (string, BenchRes, error) {
+	for {
+		line, err := p.r.ReadSlice('\n')
+		p.partial = append(p.partial, line...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return "", BenchRes{}, err
+		}
+		name, res, ok, err := p.parsePartial()
+		if err != nil || ok {
+			return name, res, err
+		}
+	}
+}
+
+// parsePartial parses the line read so far, reporting whether it was a
+// benchmark result.
+func (p *Parser) parsePartial() (string, BenchRes, bool, error) {
+	p.lineNum++
+	line := bytes.TrimSuffix(p.partial, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	event, _ := textOutput(line) // never fails
+	p.partial = p.partial[:0]
+	if event.Output == "" {
+		return "", BenchRes{}, false, nil
+	}
+
+	name, res, ok, err := p.cfg.parseLine(event.Output, time.Time{})
+	if err == nil && ok && p.cfg.noDuplicateInputs {
+		err = p.dups.check(name, res.Inputs)
+	}
+	if err != nil {
+		return "", BenchRes{}, false, fmt.Errorf("line %d: %w", p.lineNum, err)
+	}
+	if ok {
+		res.sampleIndex = p.samples.next(name, res.Inputs)
+	}
+	return name, res, ok, nil
+}
+
+// BenchmarkScanner reads the results of testing.B output one at a time,
+// similar to bufio.Scanner. Unlike ParseBenchmarks, results are not
+// accumulated, so memory use is proportional to the number of distinct
+// cases (used to track the sample index of each result, and any
+// duplicates when parsing WithNoDuplicateInputs) rather than the size of
+// the output.
+//
+// Successive calls to Scan step through the results, with the current
+// result and the name of its benchmark available via Result and Name.
+// Scanning stops at the end of the input or at the first error, which
+// is available via Err. Unlike a Parser, which BenchmarkScanner is built
+// on, the end of the input is final, so a trailing line without a
+// newline is also parsed.
+type BenchmarkScanner struct {
+	p *Parser
+
+	name string
+	res  BenchRes
+	err  error // io.EOF once the end of the input is reached
+}
+
+// NewBenchmarkScanner returns a BenchmarkScanner reading testing.B
+// output from r.
+func NewBenchmarkScanner(r io.Reader, opts ...ParseOption) *BenchmarkScanner {
+	return &BenchmarkScanner{p: NewParser(r, opts...)}
+}
+
+// Scan advances to the next result, returning false once there are no
+// results left or an error is encountered. Lines which are not benchmark
+// results are skipped.
+func (s *BenchmarkScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	name, res, err := s.p.Next()
+	if err == io.EOF && len(s.p.partial) > 0 {
+		var ok bool
+		if name, res, ok, err = s.p.parsePartial(); err == nil && !ok {
+			err = io.EOF
+		}
+	}
+	if err != nil {
+		s.err = err
+		return false
+	}
+	s.name, s.res = name, res
+	return true
+}
+
+// Name returns the name of the benchmark the current result belongs to.
+func (s *BenchmarkScanner) Name() string {
+	return s.name
+}
+
+// Result returns the current result.
+func (s *BenchmarkScanner) Result() BenchRes {
+	return s.res
+}
+
+// Err returns the first error encountered while scanning, if any.
+func (s *BenchmarkScanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+// followPollInterval is how often FollowFile checks for appended data.
+var followPollInterval = 250 * time.Millisecond
+
+// FollowFile parses the results written to the file at path as it
+// grows, similar to 'tail -f', calling fn with each result. This allows
+// monitoring a benchmark run which is still in progress.
+//
+// FollowFile returns once ctx is done, even if the file is no longer
+// being written to, returning ctx.Err(). It also returns if fn or
+// reading the file returns an error, which is then returned.
+func FollowFile(ctx context.Context, path string, fn func(BenchRes) error, opts ...ParseOption) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	p := NewParser(f, opts...)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, res, err := p.Next()
+		if err == io.EOF {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(followPollInterval):
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(res); err != nil {
+			return err
+		}
+	}
+}
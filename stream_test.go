@@ -0,0 +1,245 @@
+package benchparse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// growingBuffer is an io.Reader which returns io.EOF once all of the
+// data written so far has been read, but can have more data written
+// afterwards.
+type growingBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (g *growingBuffer) Read(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.Read(p)
+}
+
+func (g *growingBuffer) WriteString(s string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.buf.WriteString(s)
+}
+
+func TestParserTemporaryEOF(t *testing.T) {
+	var (
+		r = &growingBuffer{}
+		p = NewParser(r)
+	)
+
+	next := func(expectedName, expectedInputs string, expectedErr error) {
+		t.Helper()
+		name, res, err := p.Next()
+		if err != expectedErr {
+			t.Fatalf("unexpected error\nexpected=%v\nactual=%v", expectedErr, err)
+		}
+		if err != nil {
+			return
+		}
+		if name != expectedName || res.Inputs.String() != expectedInputs {
+			t.Errorf("unexpected result\nexpected=%s%s\nactual=%s%s", expectedName, expectedInputs, name, res.Inputs)
+		}
+	}
+
+	next("", "", io.EOF)
+
+	r.WriteString("goos: linux\nBenchmarkFoo/var=1-4 100 10 ns/op\nBenchmarkFoo/var=2-4 1")
+	next("BenchmarkFoo", "/var=1-4", nil)
+	// the second line is not yet complete
+	next("", "", io.EOF)
+
+	r.WriteString("00 20 ns/op\r\n")
+	next("BenchmarkFoo", "/var=2-4", nil)
+	next("", "", io.EOF)
+
+	r.WriteString("BenchmarkBar-4 100 30 ns/op\nPASS\n")
+	next("BenchmarkBar", "-4", nil)
+	next("", "", io.EOF)
+}
+
+func TestParserErr(t *testing.T) {
+	p := NewParser(strings.NewReader(`BenchmarkFoo/var=1-4 100 10 ns/op
+BenchmarkFoo/var=2-4 200 20 ns/op
+BenchmarkFoo/var=1-4 100 10 ns/op
+`), WithNoDuplicateInputs())
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := p.Next(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	_, _, err := p.Next()
+	if !errors.Is(err, errDuplicateInputs) {
+		t.Errorf("unexpected error\nexpected=%v\nactual=%v", errDuplicateInputs, err)
+	}
+	if err != nil && !strings.HasPrefix(err.Error(), "line 3: ") {
+		t.Errorf("error missing line number: %s", err)
+	}
+}
+
+func TestFollowFile(t *testing.T) {
+	defer func(interval time.Duration) { followPollInterval = interval }(followPollInterval)
+	followPollInterval = time.Millisecond
+
+	f, err := ioutil.TempFile("", "benchparse")
+	if err != nil {
+		t.Fatalf("unexpected error creating file: %s", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	var (
+		lines = []string{
+			"BenchmarkFoo/var=1-4 100 10 ns/op\n",
+			"BenchmarkFoo/var=2-4 100 20 ns/op\n",
+			"BenchmarkFoo/var=3-4 100 30 ns/op\n",
+		}
+		received = make(chan BenchRes)
+		errStop  = errors.New("stop")
+		done     = make(chan error)
+	)
+	go func() {
+		done <- FollowFile(context.Background(), f.Name(), func(res BenchRes) error {
+			received <- res
+			if res.Inputs.String() == "/var=3-4" {
+				return errStop
+			}
+			return nil
+		})
+	}()
+
+	for i, line := range lines {
+		if _, err := f.WriteString(line); err != nil {
+			t.Fatalf("unexpected error writing file: %s", err)
+		}
+		select {
+		case res := <-received:
+			if expected := strings.Fields(line)[0][len("BenchmarkFoo"):]; res.Inputs.String() != expected {
+				t.Errorf("unexpected result %d (expected=%s, actual=%s)", i, expected, res.Inputs)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for result %d", i)
+		}
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errStop) {
+			t.Errorf("unexpected error (expected=%v, actual=%v)", errStop, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for FollowFile to return")
+	}
+}
+
+func TestFollowFileIdle(t *testing.T) {
+	defer func(interval time.Duration) { followPollInterval = interval }(followPollInterval)
+	followPollInterval = time.Millisecond
+
+	f, err := ioutil.TempFile("", "benchparse")
+	if err != nil {
+		t.Fatalf("unexpected error creating file: %s", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := f.WriteString("BenchmarkFoo/var=1-4 100 10 ns/op\n"); err != nil {
+		t.Fatalf("unexpected error writing file: %s", err)
+	}
+
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		received    = make(chan BenchRes, 1)
+		done        = make(chan error)
+	)
+	defer cancel()
+	go func() {
+		done <- FollowFile(ctx, f.Name(), func(res BenchRes) error {
+			received <- res
+			return nil
+		})
+	}()
+
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for result")
+	}
+
+	// nothing else is written, so only canceling stops following
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("unexpected error (expected=%v, actual=%v)", context.Canceled, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for FollowFile to return")
+	}
+}
+
+func TestBenchmarkScanner(t *testing.T) {
+	s := NewBenchmarkScanner(strings.NewReader(`goos: linux
+BenchmarkFoo
+BenchmarkFoo/var=1-4 100 10 ns/op
+--- BENCH: BenchmarkFoo/var=1-4
+BenchmarkBar/var=2-4 200 20 ns/op
+BenchmarkFoo/var=1-4 300 30 ns/op`))
+
+	var (
+		names   []string
+		samples []int
+	)
+	for s.Scan() {
+		names = append(names, s.Name()+s.Result().Inputs.String())
+		samples = append(samples, s.Result().SampleIndex())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"BenchmarkFoo/var=1-4", "BenchmarkBar/var=2-4", "BenchmarkFoo/var=1-4"}
+	if strings.Join(names, " ") != strings.Join(expected, " ") {
+		t.Errorf("unexpected results\nexpected=%v\nactual=%v", expected, names)
+	}
+	if expectedSamples := []int{0, 0, 1}; !reflect.DeepEqual(samples, expectedSamples) {
+		t.Errorf("unexpected sample indices\nexpected=%v\nactual=%v", expectedSamples, samples)
+	}
+}
+
+func TestBenchmarkScannerErr(t *testing.T) {
+	s := NewBenchmarkScanner(strings.NewReader(`BenchmarkFoo/var=1-4 100 10 ns/op
+BenchmarkFoo/var=2-4 200 20 ns/op
+BenchmarkFoo/var=1-4 100 10 ns/op
+BenchmarkFoo/var=3-4 300 30 ns/op`), WithNoDuplicateInputs())
+
+	n := 0
+	for s.Scan() {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("unexpected number of results (expected=2, actual=%d)", n)
+	}
+	if !errors.Is(s.Err(), errDuplicateInputs) {
+		t.Errorf("unexpected error\nexpected=%v\nactual=%v", errDuplicateInputs, s.Err())
+	}
+	if s.Err() != nil && !strings.HasPrefix(s.Err().Error(), "line 3: ") {
+		t.Errorf("error missing line number: %s", s.Err())
+	}
+	if s.Scan() {
+		t.Errorf("unexpectedly scanned after error")
+	}
+}
@@ -0,0 +1,101 @@
+package benchparse
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Table is a tabular view of a set of benchmarks with one row per
+// result. The columns are the benchmark name, the sub-benchmark
+// names, each input variable, GOMAXPROCS, the number of iterations,
+// and each output metric measured by at least one result.
+//
+// Variables are sorted by name and metrics follow the testing.B
+// output order, followed by any custom metrics (those reported via
+// testing.B.ReportMetric) sorted by unit. The header of a custom metric
+// registered with RegisterMetric with a unit includes the unit, e.g.
+// 'p99-ns (ns)'.
+// Absent variables and unmeasured metrics are left empty.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// NewTable constructs the Table for the provided benchmarks.
+func NewTable(benches []Benchmark) Table {
+	var (
+		varNames = allVarNames(benches)
+		metrics  = measuredMetricNames(benches)
+	)
+
+	header := []string{"benchmark", "subs"}
+	header = append(header, varNames...)
+	header = append(header, "procs", "iterations")
+	for _, metric := range metrics {
+		if unit := MetricUnit(metric); unit != "" {
+			metric = fmt.Sprintf("%s (%s)", metric, unit)
+		}
+		header = append(header, metric)
+	}
+
+	rows := [][]string{}
+	for _, bench := range benches {
+		for _, res := range bench.Results {
+			row := make([]string, 0, len(header))
+			row = append(row, bench.Name, res.Inputs.SubPath())
+			for _, name := range varNames {
+				row = append(row, varValueCell(res.Inputs, name))
+			}
+			row = append(row, strconv.Itoa(res.Inputs.MaxProcs), strconv.Itoa(res.Outputs.GetIterations()))
+			for _, metric := range metrics {
+				v, err := tableValue(res.Outputs, metric)
+				if err != nil {
+					row = append(row, "")
+					continue
+				}
+				row = append(row, strconv.FormatFloat(v, 'f', -1, 64))
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return Table{Header: header, Rows: rows}
+}
+
+// tableValue returns the value of a metric returned by
+// measuredMetricNames, which may be a custom metric which wasn't
+// registered with RegisterMetric.
+func tableValue(b BenchOutputs, metric string) (float64, error) {
+	if isOutputMetric(metric) {
+		return metricValue(b, metric)
+	}
+	return customValue(b, metric)
+}
+
+// allVarNames returns the names of the input variables of any of the
+// benchmarks' results, sorted by name.
+func allVarNames(benches []Benchmark) []string {
+	varSet := map[string]bool{}
+	for _, bench := range benches {
+		for _, res := range bench.Results {
+			for _, varVal := range res.Inputs.VarValues {
+				varSet[varVal.Name] = true
+			}
+		}
+	}
+	varNames := make([]string, 0, len(varSet))
+	for name := range varSet {
+		varNames = append(varNames, name)
+	}
+	sort.Strings(varNames)
+	return varNames
+}
+
+func varValueCell(b BenchInputs, name string) string {
+	v, ok := b.Value(name)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
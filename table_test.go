@@ -0,0 +1,66 @@
+package benchparse
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/benchmark/parse"
+)
+
+var newTableTests = map[string]struct {
+	benches       []Benchmark
+	expectedTable Table
+}{
+	"sample_bench": {
+		benches: []Benchmark{sampleBench},
+		expectedTable: Table{
+			Header: []string{"benchmark", "subs", "abs_val", "delta", "end_x", "start_x", "y", "procs", "iterations", "ns/op", "B/op", "allocs/op"},
+			Rows: [][]string{
+				{"BenchmarkMath", "areaUnder", "true", "0.001", "1", "-2", "sin(x)", "4", "21801", "55357", "0", "0"},
+				{"BenchmarkMath", "areaUnder", "false", "1", "2", "-1", "2x+3", "4", "88335925", "13.3", "0", "0"},
+				{"BenchmarkMath", "max", "", "0.001", "1", "-2", "2x+3", "4", "56282", "20361", "0", "0"},
+				{"BenchmarkMath", "max", "", "1", "2", "-1", "sin(x)", "4", "16381138", "62.7", "0", "0"},
+			},
+		},
+	},
+	"partially_measured": {
+		benches: []Benchmark{
+			{
+				Name: "BenchmarkFoo",
+				Results: []BenchRes{
+					{
+						Inputs:  BenchInputs{VarValues: []BenchVarValue{{Name: "a", Value: 1, position: 1}}, MaxProcs: 1},
+						Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 10, NsPerOp: 5, MBPerS: 1.5, Measured: parse.NsPerOp | parse.MBPerS}},
+					},
+				},
+			},
+			{
+				Name: "BenchmarkBar",
+				Results: []BenchRes{
+					{
+						Inputs:  BenchInputs{VarValues: []BenchVarValue{{Name: "b", Value: "x", position: 1}}, MaxProcs: 2},
+						Outputs: parsedBenchOutputs{Benchmark: parse.Benchmark{N: 20, NsPerOp: 6, Measured: parse.NsPerOp}},
+					},
+				},
+			},
+		},
+		expectedTable: Table{
+			Header: []string{"benchmark", "subs", "a", "b", "procs", "iterations", "ns/op", "MB/s"},
+			Rows: [][]string{
+				{"BenchmarkFoo", "", "1", "", "1", "10", "5", "1.5"},
+				{"BenchmarkBar", "", "", "x", "2", "20", "6", ""},
+			},
+		},
+	},
+}
+
+func TestNewTable(t *testing.T) {
+	for testName, testCase := range newTableTests {
+		t.Run(testName, func(t *testing.T) {
+			table := NewTable(testCase.benches)
+			if !reflect.DeepEqual(table, testCase.expectedTable) {
+				t.Errorf("unexpected table\nexpected:\n%v\nactual:\n%v", testCase.expectedTable, table)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package benchparse
+
+import (
+	"math"
+	"sort"
+)
+
+// NamedBenchmarks are the benchmarks parsed from a single labeled run,
+// e.g. the results for a specific commit or release.
+type NamedBenchmarks struct {
+	Label      string
+	Benchmarks []Benchmark
+}
+
+// TrendTable is the value of a single metric for each case across
+// multiple runs.
+type TrendTable struct {
+	Metric string
+	Labels []string // the label of each run
+	Rows   []TrendRow
+}
+
+// TrendRow is the value of a metric for a single case of a benchmark
+// across multiple runs.
+type TrendRow struct {
+	Benchmark string
+	Inputs    BenchInputs
+
+	// Values are the values of the metric in each run, in the same
+	// order as the TrendTable's Labels. NaN is used for runs where the
+	// case is absent or the metric wasn't measured.
+	Values []float64
+}
+
+// CompareMany compares the named metric for each case across an
+// arbitrary number of runs, for example to chart performance over
+// several releases. This is the generalization of CompareSummary to
+// more than two runs.
+//
+// Cases are matched by benchmark name and inputs, with the mean used
+// for inputs with multiple results in a run. Rows are sorted by
+// benchmark name, and cases of the same benchmark are in the order
+// they first appear.
+func CompareMany(runs []NamedBenchmarks, metric string) (TrendTable, error) {
+	type rowKey struct {
+		benchmark string
+		inputs    string
+	}
+	var (
+		labels = make([]string, len(runs))
+		rows   = []TrendRow{}
+		index  = map[rowKey]int{}
+	)
+	for i, run := range runs {
+		labels[i] = run.Label
+		for _, bench := range run.Benchmarks {
+			means, err := meanByInputs(bench.Results, metric)
+			if err != nil {
+				return TrendTable{}, err
+			}
+			for _, m := range means {
+				k := rowKey{benchmark: bench.Name, inputs: m.inputs.key()}
+				j, ok := index[k]
+				if !ok {
+					j = len(rows)
+					index[k] = j
+					rows = append(rows, newTrendRow(bench.Name, m.inputs, len(runs)))
+				}
+				rows[j].Values[i] = m.mean
+			}
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Benchmark < rows[j].Benchmark
+	})
+	return TrendTable{Metric: metric, Labels: labels, Rows: rows}, nil
+}
+
+func newTrendRow(benchmark string, inputs BenchInputs, numRuns int) TrendRow {
+	values := make([]float64, numRuns)
+	for i := range values {
+		values[i] = math.NaN()
+	}
+	return TrendRow{Benchmark: benchmark, Inputs: inputs, Values: values}
+}
@@ -0,0 +1,95 @@
+package benchparse
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+var compareManyTests = map[string]struct {
+	runs           []NamedBenchmarks
+	metric         string
+	expectedLabels []string
+	expectedRows   []TrendRow
+	expectedErr    error
+}{
+	"three_runs": {
+		runs: []NamedBenchmarks{
+			{Label: "v1.0", Benchmarks: []Benchmark{withMetric(sampleBench, "ns/op", 100, 200)}},
+			{Label: "v1.1", Benchmarks: []Benchmark{
+				withMetric(sampleBench, "ns/op", 90, 210, 300),
+				{Name: "BenchmarkAbc", Results: withMetric(sampleBench, "ns/op", 5).Results},
+			}},
+			{Label: "v1.2", Benchmarks: []Benchmark{withMetric(sampleBench, "ns/op", 80)}},
+		},
+		metric:         "ns/op",
+		expectedLabels: []string{"v1.0", "v1.1", "v1.2"},
+		expectedRows: []TrendRow{
+			{Benchmark: "BenchmarkAbc", Inputs: sampleBench.Results[0].Inputs, Values: []float64{math.NaN(), 5, math.NaN()}},
+			{Benchmark: "BenchmarkMath", Inputs: sampleBench.Results[0].Inputs, Values: []float64{100, 90, 80}},
+			{Benchmark: "BenchmarkMath", Inputs: sampleBench.Results[1].Inputs, Values: []float64{200, 210, math.NaN()}},
+			{Benchmark: "BenchmarkMath", Inputs: sampleBench.Results[2].Inputs, Values: []float64{math.NaN(), 300, math.NaN()}},
+		},
+	},
+	"repeated_inputs": {
+		runs: []NamedBenchmarks{
+			{Label: "a", Benchmarks: []Benchmark{{Name: "BenchmarkMath", Results: append(withMetric(sampleBench, "ns/op", 100).Results, withMetric(sampleBench, "ns/op", 200).Results...)}}},
+		},
+		metric:         "ns/op",
+		expectedLabels: []string{"a"},
+		expectedRows: []TrendRow{
+			{Benchmark: "BenchmarkMath", Inputs: sampleBench.Results[0].Inputs, Values: []float64{150}},
+		},
+	},
+	"unknown_metric": {
+		runs:        []NamedBenchmarks{{Label: "a", Benchmarks: []Benchmark{sampleBench}}},
+		metric:      "foo/op",
+		expectedErr: errUnknownMetric,
+	},
+}
+
+func TestCompareMany(t *testing.T) {
+	for testName, testCase := range compareManyTests {
+		t.Run(testName, func(t *testing.T) {
+			table, err := CompareMany(testCase.runs, testCase.metric)
+			if !errors.Is(err, testCase.expectedErr) {
+				t.Fatalf("unexpected error\nexpected=%v\nactual=%v", testCase.expectedErr, err)
+			}
+			if testCase.expectedErr != nil {
+				return
+			}
+			if table.Metric != testCase.metric {
+				t.Errorf("unexpected metric (expected=%s, actual=%s)", testCase.metric, table.Metric)
+			}
+			if !reflect.DeepEqual(table.Labels, testCase.expectedLabels) {
+				t.Errorf("unexpected labels\nexpected:\n%v\nactual:\n%v", testCase.expectedLabels, table.Labels)
+			}
+			if len(table.Rows) != len(testCase.expectedRows) {
+				t.Fatalf("unexpected number of rows (expected=%d, actual=%d)\n%v", len(testCase.expectedRows), len(table.Rows), table.Rows)
+			}
+			for i, row := range table.Rows {
+				expected := testCase.expectedRows[i]
+				if row.Benchmark != expected.Benchmark || row.Inputs.key() != expected.Inputs.key() {
+					t.Errorf("unexpected case for row %d (expected=%s%s, actual=%s%s)", i, expected.Benchmark, expected.Inputs, row.Benchmark, row.Inputs)
+				}
+				if !floatsEqual(row.Values, expected.Values) {
+					t.Errorf("unexpected values for row %d\nexpected:\n%v\nactual:\n%v", i, expected.Values, row.Values)
+				}
+			}
+		})
+	}
+}
+
+// floatsEqual compares a and b, treating NaNs as equal.
+func floatsEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] && !(math.IsNaN(a[i]) && math.IsNaN(b[i])) {
+			return false
+		}
+	}
+	return true
+}